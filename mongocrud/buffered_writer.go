@@ -0,0 +1,159 @@
+package mongocrud
+
+import (
+	// Standard
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// BufferedWriter accumulates documents and inserts them into a collection in batches, flushing whenever a batch is full
+// or the flush interval elapses
+type BufferedWriter struct {
+	collection *DatabaseCollection
+	maxBatch   int
+
+	mu     sync.Mutex
+	buffer []interface{}
+	err    error
+	closed bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewBufferedWriter creates a writer for the collection that flushes every maxBatch documents and every flushInterval.
+// A maxBatch or flushInterval of zero disables that trigger. Close must be called to flush the remaining documents.
+func NewBufferedWriter(coll *DatabaseCollection, maxBatch int, flushInterval time.Duration) *BufferedWriter {
+	w := &BufferedWriter{
+		collection: coll,
+		maxBatch:   maxBatch,
+		done:       make(chan struct{}),
+	}
+
+	if flushInterval > 0 {
+		w.wg.Add(1)
+		go w.run(flushInterval)
+	}
+
+	return w
+}
+
+// run flushes the buffer on every tick until the writer is closed, keeping any error for the next caller
+func (w *BufferedWriter) run(flushInterval time.Duration) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			if err := w.insert(context.Background(), w.take(0)); err != nil {
+				w.mu.Lock()
+				w.err = err
+				w.mu.Unlock()
+			}
+		}
+	}
+}
+
+// Write adds a document to the buffer, inserting the batch when it reaches the maximum size
+func (w *BufferedWriter) Write(ctx context.Context, doc interface{}) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return ErrorWriterClosed
+	}
+
+	w.buffer = append(w.buffer, doc)
+	w.mu.Unlock()
+
+	if w.maxBatch <= 0 {
+		return nil
+	}
+
+	return w.insert(ctx, w.take(w.maxBatch))
+}
+
+// Flush inserts every buffered document, returning the first error seen since the last flush
+func (w *BufferedWriter) Flush(ctx context.Context) error {
+	err := w.insert(ctx, w.take(0))
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err != nil {
+		err, w.err = w.err, nil
+	}
+
+	return err
+}
+
+// Close stops the interval flush, rejects further writes and flushes the remaining documents. When the flush fails
+// the documents that were not inserted stay buffered, and calling Close again retries them.
+func (w *BufferedWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	closing := !w.closed
+	w.closed = true
+	w.mu.Unlock()
+
+	if closing {
+		close(w.done)
+		w.wg.Wait()
+	}
+
+	return w.Flush(ctx)
+}
+
+// take removes and returns the buffered documents once there are at least min of them
+func (w *BufferedWriter) take(min int) []interface{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buffer) == 0 || (min > 0 && len(w.buffer) < min) {
+		return nil
+	}
+
+	batch := w.buffer
+	w.buffer = nil
+
+	return batch
+}
+
+// insert inserts the batch, putting the documents that were not inserted back at the front of the buffer so that a
+// later flush or Close retries them. A document that would fail again, such as a duplicate the server rejected or one
+// over MaxDocSize, is dropped and reported by the returned error, as is the whole batch on a ReadOnly collection.
+func (w *BufferedWriter) insert(ctx context.Context, batch []interface{}) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	result, err := w.collection.NewItems(ctx, batch)
+	if err == nil {
+		return nil
+	}
+
+	var ie *itemError
+	remaining := batch
+	switch {
+	case errors.Is(err, ErrorReadOnly):
+		remaining = nil
+	case errors.As(err, &ie):
+		remaining = append(append([]interface{}(nil), batch[:ie.index]...), batch[ie.index+1:]...)
+	case result != nil:
+		// The insert is ordered, so a partial result holds the documents before the first rejected one
+		remaining = batch[len(result.InsertedIDs)+1:]
+	}
+
+	if len(remaining) > 0 {
+		w.mu.Lock()
+		w.buffer = append(append(make([]interface{}, 0, len(remaining)+len(w.buffer)), remaining...), w.buffer...)
+		w.mu.Unlock()
+	}
+
+	return err
+}
@@ -0,0 +1,137 @@
+package mongocrud
+
+import (
+	// Standard
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// insertCollection stubs InsertMany, recording the documents of every call and rejecting the ones at the indexes in
+// reject with a duplicate key error
+type insertCollection struct {
+	mongoCollection
+
+	calls  [][]interface{}
+	reject map[int]bool
+}
+
+func (m *insertCollection) InsertMany(_ context.Context, docs []interface{}, _ ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	m.calls = append(m.calls, docs)
+
+	// The driver fills in an id for every document sent, and an ordered insert stops at the first rejected one
+	result := &mongo.InsertManyResult{}
+	for n := range docs {
+		result.InsertedIDs = append(result.InsertedIDs, n)
+		if m.reject[n] {
+			m.reject = nil
+			return result, mongo.BulkWriteException{
+				WriteErrors: []mongo.BulkWriteError{{WriteError: mongo.WriteError{Index: n, Code: 11000}}},
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func names(docs []interface{}) string {
+	var resp []string
+	for _, doc := range docs {
+		resp = append(resp, doc.(bson.D)[0].Value.(string))
+	}
+
+	return strings.Join(resp, ",")
+}
+
+func TestBufferedWriterRequeuesDocumentsAfterRejectedOne(t *testing.T) {
+	mock := &insertCollection{reject: map[int]bool{1: true}}
+	w := NewBufferedWriter(&DatabaseCollection{name: "items", collection: mock}, 0, 0)
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if err := w.Write(context.Background(), bson.D{{Key: "name", Value: name}}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	if err := w.Flush(context.Background()); !errors.Is(err, ErrorInsertFailed) {
+		t.Fatalf("Flush() error = %v, want %v", err, ErrorInsertFailed)
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(mock.calls) != 2 || names(mock.calls[1]) != "c,d" {
+		t.Fatalf("InsertMany calls = %v, want the documents after the rejected one retried", mock.calls)
+	}
+}
+
+func TestBufferedWriterDropsOversizedDocument(t *testing.T) {
+	mock := &insertCollection{}
+	w := NewBufferedWriter(&DatabaseCollection{name: "items", collection: mock, MaxDocSize: 64}, 0, 0)
+
+	for _, name := range []string{"a", strings.Repeat("b", 100), "c"} {
+		if err := w.Write(context.Background(), bson.D{{Key: "name", Value: name}}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	if err := w.Flush(context.Background()); !errors.Is(err, ErrorDocumentTooLarge) {
+		t.Fatalf("Flush() error = %v, want %v", err, ErrorDocumentTooLarge)
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(mock.calls) != 1 || names(mock.calls[0]) != "a,c" {
+		t.Fatalf("InsertMany calls = %v, want the valid documents inserted", mock.calls)
+	}
+}
+
+func TestBufferedWriterDropsBatchOnReadOnlyCollection(t *testing.T) {
+	mock := &insertCollection{}
+	w := NewBufferedWriter(&DatabaseCollection{name: "items", collection: mock, ReadOnly: true}, 0, 0)
+
+	if err := w.Write(context.Background(), bson.D{{Key: "name", Value: "a"}}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if err := w.Flush(context.Background()); !errors.Is(err, ErrorReadOnly) {
+		t.Fatalf("Flush() error = %v, want %v", err, ErrorReadOnly)
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v, want the read only batch dropped", err)
+	}
+}
+
+func TestBufferedWriterGeneratesBlankIDs(t *testing.T) {
+	mock := &insertCollection{}
+	next := 0
+	coll := &DatabaseCollection{name: "items", collection: mock, IDGenerator: func() interface{} {
+		next++
+		return fmt.Sprintf("id-%d", next)
+	}}
+	w := NewBufferedWriter(coll, 0, 0)
+
+	type item struct {
+		ID string `bson:"_id"`
+	}
+	for i := 0; i < 2; i++ {
+		if err := w.Write(context.Background(), &item{}); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(mock.calls) != 1 || mock.calls[0][0].(*item).ID != "id-1" || mock.calls[0][1].(*item).ID != "id-2" {
+		t.Fatalf("InsertMany calls = %v, want every blank id generated", mock.calls)
+	}
+}
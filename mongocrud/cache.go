@@ -0,0 +1,166 @@
+package mongocrud
+
+import (
+	// Standard
+	"context"
+	"sync"
+	"time"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CachedCollection is a DatabaseCollection that caches documents read by id for a TTL. Writes made through
+// UpdateItem, UpsertItem, UpdatePartialFromStruct, UpdateArrayElement, DeleteItem, DeleteItemFromStruct and
+// SoftDeleteItem invalidate the cached document; writes through any other method are only picked up once the TTL
+// expires.
+type CachedCollection struct {
+	*DatabaseCollection
+
+	ttl time.Duration
+
+	mu    sync.Mutex
+	items map[primitive.ObjectID]cachedItem
+	calls map[primitive.ObjectID]*cacheCall
+}
+
+type cachedItem struct {
+	doc     bson.Raw
+	expires time.Time
+}
+
+// cacheCall is a read in flight that concurrent readers of the same id wait on instead of querying again
+type cacheCall struct {
+	wg    sync.WaitGroup
+	doc   bson.Raw
+	err   error
+	stale bool
+}
+
+// NewCachedCollection wraps the collection with a read-through cache whose entries live for ttl
+func NewCachedCollection(coll *DatabaseCollection, ttl time.Duration) *CachedCollection {
+	return &CachedCollection{
+		DatabaseCollection: coll,
+		ttl:                ttl,
+		items:              map[primitive.ObjectID]cachedItem{},
+		calls:              map[primitive.ObjectID]*cacheCall{},
+	}
+}
+
+// GetItem returns the document from the cache when looking up by id, loading it once for all concurrent callers on a
+// miss. Lookups by any other field, or with options, always go to the database.
+func (c *CachedCollection) GetItem(ctx context.Context, by, value string, opts ...*options.FindOneOptions) (*mongo.SingleResult, error) {
+	if (by != "id" && by != "_id") || len(opts) > 0 {
+		return c.DatabaseCollection.GetItem(ctx, by, value, opts...)
+	}
+
+	id, err := ParseID(value)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := c.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return mongo.NewSingleResultFromDocument(doc, nil, c.registry), nil
+}
+
+func (c *CachedCollection) load(ctx context.Context, id primitive.ObjectID) (bson.Raw, error) {
+	c.mu.Lock()
+	if item, ok := c.items[id]; ok && time.Now().Before(item.expires) {
+		c.mu.Unlock()
+		return item.doc, nil
+	}
+
+	if call, ok := c.calls[id]; ok {
+		c.mu.Unlock()
+		call.wg.Wait()
+		return call.doc, call.err
+	}
+
+	call := &cacheCall{}
+	call.wg.Add(1)
+	c.calls[id] = call
+	c.mu.Unlock()
+
+	item, err := c.DatabaseCollection.GetItem(ctx, "id", id.Hex())
+	if err == nil {
+		call.doc, call.err = item.DecodeBytes()
+	} else {
+		call.err = err
+	}
+
+	c.mu.Lock()
+	delete(c.calls, id)
+	// A write that landed while the read was in flight leaves the result stale, so it is not kept
+	if call.err == nil && !call.stale {
+		c.items[id] = cachedItem{doc: call.doc, expires: time.Now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+
+	call.wg.Done()
+	return call.doc, call.err
+}
+
+// Invalidate drops the cached document with the id
+func (c *CachedCollection) Invalidate(id primitive.ObjectID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.items, id)
+	if call, ok := c.calls[id]; ok {
+		call.stale = true
+	}
+}
+
+func (c *CachedCollection) UpdateItem(ctx context.Context, i interface{}) (*mongo.SingleResult, error) {
+	defer c.invalidateItem(i)
+	return c.DatabaseCollection.UpdateItem(ctx, i)
+}
+
+func (c *CachedCollection) UpsertItem(ctx context.Context, i interface{}) (*mongo.SingleResult, error) {
+	defer c.invalidateItem(i)
+	return c.DatabaseCollection.UpsertItem(ctx, i)
+}
+
+func (c *CachedCollection) UpdatePartialFromStruct(ctx context.Context, id primitive.ObjectID, i interface{}) (*mongo.SingleResult, error) {
+	defer c.Invalidate(id)
+	return c.DatabaseCollection.UpdatePartialFromStruct(ctx, id, i)
+}
+
+func (c *CachedCollection) UpdateArrayElement(ctx context.Context, id primitive.ObjectID, update bson.M, arrayFilters []interface{}) (*mongo.UpdateResult, error) {
+	defer c.Invalidate(id)
+	return c.DatabaseCollection.UpdateArrayElement(ctx, id, update, arrayFilters)
+}
+
+func (c *CachedCollection) DeleteItem(ctx context.Context, id primitive.ObjectID) error {
+	defer c.Invalidate(id)
+	return c.DatabaseCollection.DeleteItem(ctx, id)
+}
+
+func (c *CachedCollection) DeleteItemFromStruct(ctx context.Context, i interface{}) error {
+	defer c.invalidateItem(i)
+	return c.DatabaseCollection.DeleteItemFromStruct(ctx, i)
+}
+
+func (c *CachedCollection) SoftDeleteItem(ctx context.Context, id primitive.ObjectID) error {
+	defer c.Invalidate(id)
+	return c.DatabaseCollection.SoftDeleteItem(ctx, id)
+}
+
+// invalidateItem drops the cached document for the struct's ID
+func (c *CachedCollection) invalidateItem(i interface{}) {
+	idField, err := structID(i)
+	if err != nil {
+		return
+	}
+
+	if id, ok := idField.Interface().(primitive.ObjectID); ok {
+		c.Invalidate(id)
+	}
+}
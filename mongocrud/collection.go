@@ -4,144 +4,2262 @@ import (
 	// Standard
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"reflect"
+	"regexp"
+	"strings"
 	"time"
 
 	// External
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/tag"
+	"go.uber.org/zap"
 )
 
 var (
-	ErrorAlreadyExists = errors.New("item already exists")
-	ErrorInsertFailed  = errors.New("failed to insert")
-	ErrorGetFailed     = errors.New("failed to get")
-	ErrorDeleteFailed  = errors.New("failed to delete")
-	ErrorUpdateFailed  = errors.New("failed to update")
+	ErrorAlreadyExists   = errors.New("item already exists")
+	ErrorNotFound        = errors.New("item not found")
+	ErrorInsertFailed    = errors.New("failed to insert")
+	ErrorGetFailed       = errors.New("failed to get")
+	ErrorDeleteFailed    = errors.New("failed to delete")
+	ErrorUpdateFailed    = errors.New("failed to update")
+	ErrorExplainFailed   = errors.New("failed to explain")
+	ErrorAggregateFailed = errors.New("failed to aggregate")
+	ErrorIndexFailed     = errors.New("failed to create index")
+	ErrorDecodeFailed    = errors.New("failed to decode")
 
-	ErrorIdBlank = errors.New("id cannot be blank")
+	ErrorContextCancelled = errors.New("operation cancelled")
+	ErrorTimeout          = errors.New("operation timed out")
+
+	ErrorIdBlank         = errors.New("id cannot be blank")
+	ErrorIdMissing       = errors.New("failed to accept argument, must have an ID field")
+	ErrorIdInvalid       = errors.New("id is not a valid object id")
+	ErrorIdTypeMismatch  = errors.New("generated id does not fit the id field")
+	ErrorShardKeyMissing = errors.New("item is missing a shard key field")
+
+	ErrorDecimalInvalid = errors.New("value is not a valid decimal")
+
+	ErrorBatchSizeInvalid  = errors.New("batch size must be greater than zero")
+	ErrorPageSizeInvalid   = errors.New("page size must be greater than zero")
+	ErrorSampleSizeInvalid = errors.New("sample size must be greater than zero")
+	ErrorMigrationFailed   = errors.New("migration failed")
+	ErrorWriterClosed      = errors.New("buffered writer is closed")
+	ErrorTooManyDocuments  = errors.New("query matched more documents than allowed")
+	ErrorDocumentTooLarge  = errors.New("document is larger than the maximum size")
+	ErrorFormatInvalid     = errors.New("format must be json or bson")
 
 	ErrorValueNotPointer = errors.New("failed to accept argument, must be a pointer")
 	ErrorValueNotStruct  = errors.New("failed to accept argument, must be a struct")
+	ErrorValueNotSlice   = errors.New("failed to accept argument, must be a pointer to a slice")
+	ErrorValueNotMap     = errors.New("failed to accept argument, must be a pointer to a map with string keys")
+	ErrorMapKeyInvalid   = errors.New("map key field must be a string or an object id")
+	ErrorFieldsEmpty     = errors.New("failed to accept argument, at least one field is required")
+	ErrorFieldNotFound   = errors.New("struct field not found or not encoded")
+	ErrorModelInvalid    = errors.New("struct has an invalid bson mapping")
+
+	ErrorConfirmationRequired = errors.New("destructive operation requires confirmation")
+	ErrorSoftDeleteDisabled   = errors.New("soft delete field is not configured")
+	ErrorReadOnly             = errors.New("collection is read only")
+	ErrorStreamInvalidated    = errors.New("change stream was invalidated")
 )
 
+// tailRetryInterval is how long Tail waits before reopening a cursor that has died
+const tailRetryInterval = time.Second
+
 type DatabaseCollection struct {
 	name       string
 	collection mongoCollection
+	writeLocks *idLocks
+	logger     *zap.Logger
+	registry   *bsoncodec.Registry
+	timeouts   *Timeouts
+	// readTags are the client's ReadPreferenceTags, for WithTaggedReads
+	readTags map[string]string
+	// readPref is set on views that may read from a secondary, so that writes read their result back from the primary
+	readPref *readpref.ReadPref
+
+	// DefaultSort is applied to find queries that do not specify their own sort
+	DefaultSort bson.D
+	// BatchSize is the number of documents fetched per round trip by find queries that do not set their own, zero
+	// leaves the driver default
+	BatchSize int32
+	// WriteRetries is how many times NewItem, UpdateItem and DeleteItem retry a write that failed with a transient
+	// error, such as during an election, zero disables retries. Writes inside a transaction are not retried.
+	WriteRetries int
+	// WriteRetryBackoff is the delay before the first retry, doubling after each attempt
+	WriteRetryBackoff time.Duration
+	// IDGenerator creates the id of items inserted with a blank ID, such as a time-sortable ULID, and must return a
+	// value of the ID field's type. When nil, new ObjectIDs are generated. The struct methods use the ID field as is
+	// whatever its type; GetItem only parses ObjectIDs, so look other ids up with GetItemByID.
+	IDGenerator func() interface{}
+	// MaxQueryTime has the server abort find queries that run longer than it unless they set their own limit, zero
+	// leaves queries unbounded
+	MaxQueryTime time.Duration
+	// Comment is attached to find and aggregate operations that do not set their own, so they can be attributed to
+	// their code path in the profiler and server logs
+	Comment string
+	// SlowQueryThreshold logs every operation that takes longer than it, zero disables the log
+	SlowQueryThreshold time.Duration
+	// NonAtomicDeletes runs DeleteItemsReturning without a transaction, for deployments such as standalone servers
+	// that do not support them
+	NonAtomicDeletes bool
+	// ReadOnly makes every method that writes, including index creation and aggregations with an $out or $merge
+	// stage, return ErrorReadOnly without contacting the server, as a safeguard for processes that must never write
+	ReadOnly bool
+	// ReadFallbackToSecondary retries GetItem, GetItems, Exists and CountItems on a secondary when they fail because
+	// there is no primary, serving possibly stale data through an election instead of an error. Server selection waits
+	// serverSelectionTimeoutMS before failing, so lower it through URIParams for the fallback to be quick.
+	ReadFallbackToSecondary bool
+	// MaxDocSize rejects inserts of documents that encode to more than this many bytes with ErrorDocumentTooLarge
+	// before they are sent, zero disables the check. Ids the driver adds to NewItems documents are not counted.
+	MaxDocSize int
+	// ShardKey lists the bson keys of the collection's shard key, dotted for nested fields. UpdateItem, UpsertItem and
+	// DeleteItemFromStruct add them to their _id filter from the item, so the write targets one shard instead of all.
+	ShardKey []string
+	// SoftDeleteField makes SoftDeleteItem mark documents with this field instead of removing them, and leaves the
+	// marked documents out of the find, get, exists, count and sample reads. Aggregate pipelines run as given. Empty
+	// disables soft deletes.
+	SoftDeleteField string
+	// SoftDeleteFlag marks documents by setting SoftDeleteField to true, such as isDeleted, instead of to the time of
+	// the deletion, such as deleted_at
+	SoftDeleteFlag bool
 }
 
 type mongoCollection interface {
+	Database() *mongo.Database
+	Indexes() mongo.IndexView
+	Clone(...*options.CollectionOptions) (*mongo.Collection, error)
+
 	InsertOne(context.Context, interface{}, ...*options.InsertOneOptions) (*mongo.InsertOneResult, error)
+	InsertMany(context.Context, []interface{}, ...*options.InsertManyOptions) (*mongo.InsertManyResult, error)
 	FindOne(context.Context, interface{}, ...*options.FindOneOptions) *mongo.SingleResult
+	Find(context.Context, interface{}, ...*options.FindOptions) (*mongo.Cursor, error)
+	CountDocuments(context.Context, interface{}, ...*options.CountOptions) (int64, error)
+	Aggregate(context.Context, interface{}, ...*options.AggregateOptions) (*mongo.Cursor, error)
+	Watch(context.Context, interface{}, ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error)
+	UpdateOne(context.Context, interface{}, interface{}, ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	FindOneAndUpdate(context.Context, interface{}, interface{}, ...*options.FindOneAndUpdateOptions) *mongo.SingleResult
+	FindOneAndReplace(context.Context, interface{}, interface{}, ...*options.FindOneAndReplaceOptions) *mongo.SingleResult
 	ReplaceOne(context.Context, interface{}, interface{}, ...*options.ReplaceOptions) (*mongo.UpdateResult, error)
 	DeleteOne(context.Context, interface{}, ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	DeleteMany(context.Context, interface{}, ...*options.DeleteOptions) (*mongo.DeleteResult, error)
+	BulkWrite(context.Context, []mongo.WriteModel, ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error)
+}
+
+// notFoundError is ErrorNotFound for an operation whose failures are otherwise reported as one of fallbacks, so that
+// it matches every one of them with errors.Is and callers checking any of them keep working
+type notFoundError struct {
+	fallbacks []error
+}
+
+// notFound returns ErrorNotFound for an operation whose failures are reported as the fallbacks
+func notFound(fallbacks ...error) error {
+	return notFoundError{fallbacks: fallbacks}
+}
+
+func (e notFoundError) Error() string {
+	return ErrorNotFound.Error()
+}
+
+func (e notFoundError) Is(target error) bool {
+	if target == ErrorNotFound {
+		return true
+	}
+
+	for _, fallback := range e.fallbacks {
+		if target == fallback {
+			return true
+		}
+	}
+
+	return false
+}
+
+// operationError maps a failed driver call onto fallback, keeping a cancelled context and a timeout apart so callers
+// can tell a client disconnect from a slow query. A missing document is reported as ErrorNotFound and still satisfies
+// errors.Is(err, fallback), so a GetItem miss is both ErrorNotFound and ErrorGetFailed.
+func operationError(ctx context.Context, err, fallback error) error {
+	resp := fallback
+
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		resp = notFound(fallback)
+	case errors.Is(err, context.Canceled), errors.Is(ctx.Err(), context.Canceled):
+		resp = ErrorContextCancelled
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(ctx.Err(), context.DeadlineExceeded), mongo.IsTimeout(err):
+		resp = ErrorTimeout
+	}
+
+	return resp
+}
+
+// secondaryFallback returns the collection reading from secondaries to retry a read on, when ReadFallbackToSecondary
+// is set and the read failed for want of a primary
+func (c *DatabaseCollection) secondaryFallback(err error) (mongoCollection, bool) {
+	if !c.ReadFallbackToSecondary || err == nil || !isNoPrimaryError(err) {
+		return nil, false
+	}
+
+	collection, cerr := c.collection.Clone(options.Collection().SetReadPreference(readpref.SecondaryPreferred()))
+	if cerr != nil {
+		return nil, false
+	}
+
+	if c.logger != nil {
+		c.logger.Warn("no primary, reading from a secondary",
+			zap.String("collection", c.name),
+			zap.Error(err),
+		)
+	}
+
+	return collection, true
+}
+
+// operationKind selects which of the client's Timeouts bounds an operation
+type operationKind int
+
+const (
+	readOperation operationKind = iota
+	writeOperation
+	deleteOperation
+)
+
+// withTimeout bounds ctx by the client's timeout for the kind of operation, unless the caller already set a deadline
+// or the timeout is zero
+func (c *DatabaseCollection) withTimeout(ctx context.Context, kind operationKind) (context.Context, context.CancelFunc) {
+	if c.timeouts == nil {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+
+	var timeout time.Duration
+	switch kind {
+	case readOperation:
+		timeout = c.timeouts.Read
+	case writeOperation:
+		timeout = c.timeouts.Write
+	case deleteOperation:
+		timeout = c.timeouts.Delete
+	}
+
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// observe counts the operation and the error it returned, when err is set, and logs it when it started longer than
+// SlowQueryThreshold ago. Deferred with a pointer to the named error result, it counts each failure once at the public
+// method that returns it.
+func (c *DatabaseCollection) observe(operation string, start time.Time, err *error) {
+	countOperation(c.name, operation)
+	if err != nil {
+		countError(*err)
+	}
+
+	if c.SlowQueryThreshold <= 0 || c.logger == nil {
+		return
+	}
+
+	if elapsed := time.Since(start); elapsed > c.SlowQueryThreshold {
+		c.logger.Warn("slow query",
+			zap.String("collection", c.name),
+			zap.String("operation", operation),
+			zap.Duration("elapsed", elapsed),
+		)
+	}
+}
+
+// liveFilter narrows filter to the documents that have not been soft deleted
+func (c *DatabaseCollection) liveFilter(filter interface{}) interface{} {
+	if c.SoftDeleteField == "" {
+		return filter
+	}
+
+	// A null match also covers documents written before the field existed
+	live := bson.D{{Key: c.SoftDeleteField, Value: nil}}
+	if c.SoftDeleteFlag {
+		live = bson.D{{Key: c.SoftDeleteField, Value: bson.D{{Key: "$ne", Value: true}}}}
+	}
+
+	if filter == nil {
+		return live
+	}
+
+	return bson.D{{Key: "$and", Value: bson.A{filter, live}}}
+}
+
+// marshal encodes v with the collection's registry, as the driver would
+func (c *DatabaseCollection) marshal(v interface{}) (bson.Raw, error) {
+	registry := c.registry
+	if registry == nil {
+		registry = bson.DefaultRegistry
+	}
+
+	return bson.MarshalWithRegistry(registry, v)
 }
 
-func (c *DatabaseCollection) NewItem(ctx context.Context, i interface{}) (*mongo.SingleResult, error) {
+// checkDocSize returns ErrorDocumentTooLarge when the document encodes to more than MaxDocSize bytes
+func (c *DatabaseCollection) checkDocSize(doc interface{}) error {
+	if c.MaxDocSize <= 0 {
+		return nil
+	}
+
+	raw, err := c.marshal(doc)
+	if err != nil {
+		return ErrorInsertFailed
+	}
+
+	if len(raw) > c.MaxDocSize {
+		return fmt.Errorf("%w: %d bytes is over the limit of %d", ErrorDocumentTooLarge, len(raw), c.MaxDocSize)
+	}
+
+	return nil
+}
+
+// shardKeyFilter returns the filter elements for the ShardKey fields of the item, read from the item as it would be
+// stored. It returns ErrorShardKeyMissing when the item does not encode one of them.
+func (c *DatabaseCollection) shardKeyFilter(i interface{}) (bson.D, error) {
+	if len(c.ShardKey) == 0 {
+		return nil, nil
+	}
+
+	doc, err := c.marshal(i)
+	if err != nil {
+		return nil, ErrorUpdateFailed
+	}
+
+	resp := make(bson.D, 0, len(c.ShardKey))
+	for _, key := range c.ShardKey {
+		value, err := bson.Raw(doc).LookupErr(strings.Split(key, ".")...)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrorShardKeyMissing, key)
+		}
+
+		resp = append(resp, bson.E{Key: key, Value: value})
+	}
+
+	return resp, nil
+}
+
+// structID validates that i is a pointer to a struct and returns its ID field
+func structID(i interface{}) (reflect.Value, error) {
 	rv := reflect.ValueOf(i)
 
 	if rv.Kind() != reflect.Ptr {
-		return nil, ErrorValueNotPointer
+		return reflect.Value{}, ErrorValueNotPointer
 	}
 
 	tgt := rv.Elem()
 	if tgt.Kind() != reflect.Struct {
-		return nil, ErrorValueNotStruct
+		return reflect.Value{}, ErrorValueNotStruct
 	}
 
-	if tgt.FieldByName("ID").Interface().(primitive.ObjectID) == primitive.NilObjectID {
-		return nil, ErrorIdBlank
+	id := tgt.FieldByName("ID")
+	if !id.IsValid() {
+		return reflect.Value{}, ErrorIdMissing
+	}
+
+	return id, nil
+}
+
+// withoutID marshals i with the collection's registry and strips its _id so that Mongo generates one on insert. The
+// other values are kept encoded, so they are not decoded again through the default registry.
+func (c *DatabaseCollection) withoutID(i interface{}) (bson.D, error) {
+	raw, err := c.marshal(i)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err := c.collection.InsertOne(ctx, i)
+	elements, err := raw.Elements()
 	if err != nil {
-		return nil, ErrorInsertFailed
+		return nil, err
+	}
+
+	resp := make(bson.D, 0, len(elements))
+	for _, e := range elements {
+		if e.Key() != "_id" {
+			resp = append(resp, bson.E{Key: e.Key(), Value: e.Value()})
+		}
 	}
 
-	return c.GetItem(ctx, "id", tgt.FieldByName("ID").Interface().(primitive.ObjectID).Hex())
+	return resp, nil
 }
 
-func (c *DatabaseCollection) ItemExists(ctx context.Context, by, value string) bool {
-	var filter primitive.D
+// NewItem inserts the item and returns the stored document. An item with a blank ID is given one from IDGenerator,
+// written back into the item.
+func (c *DatabaseCollection) NewItem(ctx context.Context, i interface{}) (_ *mongo.SingleResult, err error) {
+	defer c.observe("NewItem", time.Now(), &err)
+
+	if c.ReadOnly {
+		return nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	idField, err := structID(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if idField.IsZero() {
+		if err := c.generateID(idField); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.checkDocSize(i); err != nil {
+		return nil, err
+	}
+
+	err = c.retryWrite(ctx, func() error {
+		_, err := c.collection.InsertOne(ctx, i)
+		return err
+	})
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorInsertFailed)
+	}
+
+	return c.getByID(ctx, idField.Interface())
+}
+
+// InsertRaw inserts a document as is, such as a webhook payload, including keys that contain dots or start with a
+// dollar sign. The driver does not check the keys of inserted documents and the server accepts them from MongoDB 5.0,
+// but such fields cannot be matched by a plain filter or set by an update operator; read them back whole or with
+// $getField. A missing _id is given a new ObjectID.
+func (c *DatabaseCollection) InsertRaw(ctx context.Context, doc bson.M) (_ *mongo.InsertOneResult, err error) {
+	defer c.observe("InsertRaw", time.Now(), &err)
+
+	if c.ReadOnly {
+		return nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	if _, ok := doc["_id"]; !ok {
+		doc["_id"] = primitive.NewObjectID()
+	}
+
+	if err := c.checkDocSize(doc); err != nil {
+		return nil, err
+	}
+
+	var result *mongo.InsertOneResult
+	err = c.retryWrite(ctx, func() (err error) {
+		result, err = c.collection.InsertOne(ctx, doc)
+		return err
+	})
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorInsertFailed)
+	}
+
+	return result, nil
+}
+
+// generateID sets the ID field to a new id from IDGenerator, or a new ObjectID when no generator is set
+func (c *DatabaseCollection) generateID(idField reflect.Value) error {
+	var id interface{} = primitive.NewObjectID()
+	if c.IDGenerator != nil {
+		id = c.IDGenerator()
+	}
+
+	rv := reflect.ValueOf(id)
+	if !idField.CanSet() || !rv.IsValid() || !rv.Type().AssignableTo(idField.Type()) {
+		return ErrorIdTypeMismatch
+	}
 
+	idField.Set(rv)
+	return nil
+}
+
+// getByID returns the document with the _id, whatever type the id is, reading from the primary so that it sees the
+// write that preceded it
+func (c *DatabaseCollection) getByID(ctx context.Context, id interface{}) (*mongo.SingleResult, error) {
+	item := c.primary().FindOne(ctx, c.liveFilter(bson.D{{Key: "_id", Value: id}}), c.findOneOptions())
+	if err := item.Err(); err != nil {
+		return nil, operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return item, nil
+}
+
+// NewItems inserts all items in a single round trip. Struct pointers with a blank ID are given one from IDGenerator, as
+// in NewItem. When some of the documents are rejected, such as duplicates, the result is returned alongside the error
+// and lists only the ids that were inserted. An ordered insert stops at the first rejected document, while
+// SetOrdered(false) carries on with the rest.
+func (c *DatabaseCollection) NewItems(ctx context.Context, items []interface{}, opts ...*options.InsertManyOptions) (_ *mongo.InsertManyResult, err error) {
+	defer c.observe("NewItems", time.Now(), &err)
+
+	return c.newItems(ctx, items, opts...)
+}
+
+// newItems is NewItems for the observed operations that insert in batches, such as Import, so their failures are
+// counted once
+func (c *DatabaseCollection) newItems(ctx context.Context, items []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	if c.ReadOnly {
+		return nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	for n, item := range items {
+		// Other items, such as maps and documents, are sent as they are and the driver adds a missing _id
+		if idField, err := structID(item); err == nil && idField.IsZero() {
+			if err := c.generateID(idField); err != nil {
+				return nil, &itemError{index: n, err: err}
+			}
+		}
+
+		if err := c.checkDocSize(item); err != nil {
+			return nil, &itemError{index: n, err: err}
+		}
+	}
+
+	result, err := c.collection.InsertMany(ctx, items, opts...)
+
+	var bwe mongo.BulkWriteException
+	if err != nil && result != nil && errors.As(err, &bwe) && len(bwe.WriteErrors) > 0 {
+		ordered := options.MergeInsertManyOptions(opts...).Ordered
+		return insertedOnly(result, bwe, ordered == nil || *ordered), fmt.Errorf("%w: %v", ErrorInsertFailed, err)
+	}
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorInsertFailed)
+	}
+
+	return result, nil
+}
+
+// itemError is the failure of one of the items passed to NewItems, found before any of them was sent
+type itemError struct {
+	index int
+	err   error
+}
+
+func (e *itemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.index, e.err)
+}
+
+func (e *itemError) Unwrap() error {
+	return e.err
+}
+
+// insertedOnly drops the ids of the documents that were not inserted from a failed insert's result, which the driver
+// fills with the id of every document sent. An ordered insert never attempts the documents after its first failure.
+func insertedOnly(result *mongo.InsertManyResult, bwe mongo.BulkWriteException, ordered bool) *mongo.InsertManyResult {
+	failed := make(map[int]bool, len(bwe.WriteErrors))
+	first := len(result.InsertedIDs)
+	for _, we := range bwe.WriteErrors {
+		failed[we.Index] = true
+		if we.Index < first {
+			first = we.Index
+		}
+	}
+
+	resp := &mongo.InsertManyResult{}
+	for n, id := range result.InsertedIDs {
+		if ordered && n >= first {
+			break
+		}
+		if !failed[n] {
+			resp.InsertedIDs = append(resp.InsertedIDs, id)
+		}
+	}
+
+	return resp
+}
+
+// ParseID converts a hex string into an ObjectID
+func ParseID(value string) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		return primitive.NilObjectID, ErrorIdInvalid
+	}
+
+	return id, nil
+}
+
+// filterBy builds the equality filter for a field and value, treating "id" and "_id" as the document's ObjectID
+func filterBy(by, value string) (bson.D, error) {
 	switch by {
 	case "_id", "id":
-		objID, _ := primitive.ObjectIDFromHex(value)
-		filter = bson.D{{Key: "_id", Value: objID}}
+		id, err := ParseID(value)
+		if err != nil {
+			return nil, err
+		}
+		return bson.D{{Key: "_id", Value: id}}, nil
 	default:
-		filter = bson.D{primitive.E{Key: by, Value: value}}
+		return bson.D{{Key: by, Value: value}}, nil
+	}
+}
+
+func (c *DatabaseCollection) ItemExists(ctx context.Context, by, value string) bool {
+	defer c.observe("ItemExists", time.Now(), nil)
+
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	filter, err := filterBy(by, value)
+	if err != nil {
+		return false
 	}
 
-	result := c.collection.FindOne(ctx, filter)
+	result := c.collection.FindOne(ctx, c.liveFilter(filter), c.findOneOptions())
 	return result.Err() == nil
 }
 
-func (c *DatabaseCollection) GetItem(ctx context.Context, by, value string) (*mongo.SingleResult, error) {
-	var filter primitive.D
+// Exists reports whether any document matches the filter. Unlike ItemExists, a failed query is returned as an error
+// wrapping ErrorGetFailed rather than reported as a missing document.
+func (c *DatabaseCollection) Exists(ctx context.Context, filter bson.D) (_ bool, err error) {
+	defer c.observe("Exists", time.Now(), &err)
 
-	switch by {
-	case "_id", "id":
-		objID, _ := primitive.ObjectIDFromHex(value)
-		filter = bson.D{{Key: "_id", Value: objID}}
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	opts := options.FindOne().SetProjection(bson.D{{Key: "_id", Value: 1}})
+
+	err = c.collection.FindOne(ctx, c.liveFilter(filter), c.findOneOptions(opts)).Err()
+	if fallback, ok := c.secondaryFallback(err); ok {
+		err = fallback.FindOne(ctx, c.liveFilter(filter), c.findOneOptions(opts)).Err()
+	}
+
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return false, nil
 	default:
-		filter = bson.D{primitive.E{Key: by, Value: value}}
+		return false, operationError(ctx, err, fmt.Errorf("%w: %v", ErrorGetFailed, err))
 	}
+}
 
-	item := c.collection.FindOne(ctx, filter)
-	if item.Err() != nil {
-		return nil, ErrorGetFailed
+// WhichExist reports which of the values are present in the field using a single query. Values for an "id" or "_id"
+// field that are not valid ObjectIDs are reported as missing.
+func (c *DatabaseCollection) WhichExist(ctx context.Context, field string, values []string) (_ map[string]bool, err error) {
+	defer c.observe("WhichExist", time.Now(), &err)
+
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	resp := make(map[string]bool, len(values))
+	for _, value := range values {
+		resp[value] = false
 	}
 
-	return item, nil
-}
+	isID := field == "id" || field == "_id"
+	if isID {
+		field = "_id"
+	}
 
-func (c *DatabaseCollection) UpdateItem(ctx context.Context, i interface{}) (*mongo.SingleResult, error) {
-	rv := reflect.ValueOf(i)
+	// Ids are matched back to the value they were parsed from, whatever its hex case
+	ids := map[primitive.ObjectID]string{}
 
-	if rv.Kind() != reflect.Ptr {
-		return nil, ErrorValueNotPointer
+	candidates := make(bson.A, 0, len(values))
+	for _, value := range values {
+		if !isID {
+			candidates = append(candidates, value)
+		} else if id, err := ParseID(value); err == nil {
+			candidates = append(candidates, id)
+			ids[id] = value
+		}
 	}
 
-	tgt := rv.Elem()
-	if tgt.Kind() != reflect.Struct {
-		return nil, ErrorValueNotStruct
+	if len(candidates) == 0 {
+		return resp, nil
 	}
 
-	if tgt.FieldByName("ID").Interface().(primitive.ObjectID) == primitive.NilObjectID {
-		return nil, ErrorIdBlank
+	filter := bson.D{{Key: field, Value: bson.D{{Key: "$in", Value: candidates}}}}
+	opts := options.Find().SetProjection(bson.D{{Key: field, Value: 1}})
+
+	cursor, err := c.collection.Find(ctx, c.liveFilter(filter), c.findOptions(opts))
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorGetFailed)
 	}
+	defer cursor.Close(ctx)
 
-	id := tgt.FieldByName("ID").Interface().(primitive.ObjectID)
+	for cursor.Next(ctx) {
+		value := cursor.Current.Lookup(strings.Split(field, ".")...)
 
-	filter := bson.D{{Key: "_id", Value: id}}
+		if id, ok := value.ObjectIDOK(); ok && isID {
+			resp[ids[id]] = true
+		} else if s, ok := value.StringValueOK(); ok {
+			resp[s] = true
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return resp, nil
+}
+
+// GetItem returns the first document whose field matches the value; options such as a collation for
+// case-insensitive matching are passed through to FindOne. An "id" or "_id" value is parsed as an ObjectID. The driver
+// has no per-call read concern option, so a read needing one, such as a linearizable read, goes through a
+// WithReadConcern view.
+func (c *DatabaseCollection) GetItem(ctx context.Context, by, value string, opts ...*options.FindOneOptions) (_ *mongo.SingleResult, err error) {
+	defer c.observe("GetItem", time.Now(), &err)
+
+	return c.getItem(ctx, by, value, opts...)
+}
+
+// getItem is GetItem for the observed operations built on it, so their failures are counted once
+func (c *DatabaseCollection) getItem(ctx context.Context, by, value string, opts ...*options.FindOneOptions) (*mongo.SingleResult, error) {
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
 
-	_, err := c.collection.ReplaceOne(ctx, filter, i)
+	filter, err := filterBy(by, value)
 	if err != nil {
-		return nil, ErrorUpdateFailed
+		return nil, err
 	}
 
-	return c.GetItem(ctx, "id", tgt.FieldByName("ID").Interface().(primitive.ObjectID).Hex())
+	item := c.collection.FindOne(ctx, c.liveFilter(filter), c.findOneOptions(opts...))
+	if fallback, ok := c.secondaryFallback(item.Err()); ok {
+		item = fallback.FindOne(ctx, c.liveFilter(filter), c.findOneOptions(opts...))
+	}
+	if err := item.Err(); err != nil {
+		return nil, operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return item, nil
 }
 
-func (c *DatabaseCollection) DeleteItem(id primitive.ObjectID) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+// GetItemByID returns the document with the _id, whatever type the id is, such as a string from IDGenerator
+func (c *DatabaseCollection) GetItemByID(ctx context.Context, id interface{}, opts ...*options.FindOneOptions) (_ *mongo.SingleResult, err error) {
+	defer c.observe("GetItemByID", time.Now(), &err)
+
+	ctx, cancel := c.withTimeout(ctx, readOperation)
 	defer cancel()
 
 	filter := bson.D{{Key: "_id", Value: id}}
 
-	_, err := c.collection.DeleteOne(ctx, filter)
+	item := c.collection.FindOne(ctx, c.liveFilter(filter), c.findOneOptions(opts...))
+	if fallback, ok := c.secondaryFallback(item.Err()); ok {
+		item = fallback.FindOne(ctx, c.liveFilter(filter), c.findOneOptions(opts...))
+	}
+	if err := item.Err(); err != nil {
+		return nil, operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return item, nil
+}
+
+// GetItemRaw returns the first document whose field matches the value undecoded, so a discriminator field can be
+// read with Lookup before choosing the type to unmarshal it into
+func (c *DatabaseCollection) GetItemRaw(ctx context.Context, by, value string) (_ bson.Raw, err error) {
+	defer c.observe("GetItemRaw", time.Now(), &err)
+
+	item, err := c.getItem(ctx, by, value)
 	if err != nil {
-		return ErrorDeleteFailed
+		return nil, err
 	}
 
-	return nil
+	raw, err := item.DecodeBytes()
+	if err != nil {
+		return nil, ErrorDecodeFailed
+	}
+
+	return raw, nil
 }
 
-func (c *DatabaseCollection) MongoCollectionType() *mongo.Collection {
-	t := reflect.TypeOf(c.collection)
-	val := reflect.New(t)
+// GetLatest returns the document matching the filter with the highest sortField, such as a customer's most recent
+// order, or ErrorNotFound when none matches
+func (c *DatabaseCollection) GetLatest(ctx context.Context, filter bson.D, sortField string) (_ *mongo.SingleResult, err error) {
+	defer c.observe("GetLatest", time.Now(), &err)
 
-	return val.Interface().(*mongo.Collection)
+	return c.getFirstSorted(ctx, filter, bson.D{{Key: sortField, Value: -1}})
+}
+
+// GetEarliest returns the document matching the filter with the lowest sortField, or ErrorNotFound when none matches
+func (c *DatabaseCollection) GetEarliest(ctx context.Context, filter bson.D, sortField string) (_ *mongo.SingleResult, err error) {
+	defer c.observe("GetEarliest", time.Now(), &err)
+
+	return c.getFirstSorted(ctx, filter, bson.D{{Key: sortField, Value: 1}})
+}
+
+func (c *DatabaseCollection) getFirstSorted(ctx context.Context, filter bson.D, sort bson.D) (*mongo.SingleResult, error) {
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	if filter == nil {
+		filter = bson.D{}
+	}
+
+	item := c.collection.FindOne(ctx, c.liveFilter(filter), c.findOneOptions(options.FindOne().SetSort(sort)))
+	if err := item.Err(); err != nil {
+		return nil, operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return item, nil
+}
+
+// GetItemByAny returns the first document where any of the fields matches the value, such as a user by email or
+// username. An id field is skipped when the value is not a valid ObjectID, since it cannot match.
+func (c *DatabaseCollection) GetItemByAny(ctx context.Context, value string, fields ...string) (_ *mongo.SingleResult, err error) {
+	defer c.observe("GetItemByAny", time.Now(), &err)
+
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	if len(fields) == 0 {
+		return nil, ErrorFieldsEmpty
+	}
+
+	clauses := make(bson.A, 0, len(fields))
+	for _, field := range fields {
+		filter, err := filterBy(field, value)
+		if err != nil {
+			continue
+		}
+		clauses = append(clauses, filter)
+	}
+
+	if len(clauses) == 0 {
+		return nil, ErrorGetFailed
+	}
+
+	item := c.collection.FindOne(ctx, c.liveFilter(bson.D{{Key: "$or", Value: clauses}}), c.findOneOptions())
+	if err := item.Err(); err != nil {
+		return nil, operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return item, nil
+}
+
+// SetSerializeWritesByID makes updates to the same document id from within this process run one at a time. It should
+// be set before the collection is shared between goroutines.
+func (c *DatabaseCollection) SetSerializeWritesByID(enabled bool) {
+	switch {
+	case enabled && c.writeLocks == nil:
+		c.writeLocks = newIDLocks()
+	case !enabled:
+		c.writeLocks = nil
+	}
+}
+
+// lockID takes the write lock for the id when writes are serialized and returns the function that releases it
+func (c *DatabaseCollection) lockID(id interface{}) func() {
+	if c.writeLocks == nil {
+		return func() {}
+	}
+
+	return c.writeLocks.lock(id)
+}
+
+// WithReadConcern returns a view of the collection whose operations use the read concern, for the reads that need
+// stronger guarantees than the client default, such as majority reads after a write. The view is cheap to create, so
+// a single read can use its own concern, such as c.WithReadConcern(readconcern.Linearizable()) before a GetItem.
+// Linearizable reads are always sent to the primary, the only member that can serve them.
+func (c *DatabaseCollection) WithReadConcern(rc *readconcern.ReadConcern) (*DatabaseCollection, error) {
+	opts := options.Collection().SetReadConcern(rc)
+	if rc != nil && rc.GetLevel() == readconcern.Linearizable().GetLevel() {
+		opts.SetReadPreference(readpref.Primary())
+	}
+
+	return c.view(opts)
+}
+
+// WithReadPreference returns a view of the collection whose reads use the read preference, such as
+// readpref.Nearest() for latency tolerant reads. Writes made through the view still read their result back from the
+// primary.
+func (c *DatabaseCollection) WithReadPreference(rp *readpref.ReadPref) (*DatabaseCollection, error) {
+	view, err := c.view(options.Collection().SetReadPreference(rp))
+	if err != nil {
+		return nil, err
+	}
+
+	if rp != nil && rp.Mode() != readpref.PrimaryMode {
+		view.readPref = rp
+	}
+
+	return view, nil
+}
+
+// WithTaggedReads returns a view of the collection whose reads go to the secondaries carrying the client's
+// ReadPreferenceTags, falling back to the primary when none is available
+func (c *DatabaseCollection) WithTaggedReads() (*DatabaseCollection, error) {
+	return c.WithReadPreference(readpref.SecondaryPreferred(readpref.WithTagSets(tag.NewTagSetFromMap(c.readTags))))
+}
+
+// view returns a copy of the collection whose operations use the collection options
+func (c *DatabaseCollection) view(opts *options.CollectionOptions) (*DatabaseCollection, error) {
+	collection, err := c.collection.Clone(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	view := *c
+	view.collection = collection
+
+	return &view, nil
+}
+
+// primary returns the collection reading from the primary, for writes to read back what they wrote even through a
+// view that reads from secondaries
+func (c *DatabaseCollection) primary() mongoCollection {
+	if c.readPref == nil {
+		return c.collection
+	}
+
+	collection, err := c.collection.Clone(options.Collection().SetReadPreference(readpref.Primary()))
+	if err != nil {
+		return c.collection
+	}
+
+	return collection
+}
+
+// findOptions merges the caller's find options and fills in the collection defaults they leave unset
+func (c *DatabaseCollection) findOptions(opts ...*options.FindOptions) *options.FindOptions {
+	resp := options.MergeFindOptions(opts...)
+
+	if resp.Sort == nil && len(c.DefaultSort) > 0 {
+		resp.SetSort(c.DefaultSort)
+	}
+
+	if resp.BatchSize == nil && c.BatchSize > 0 {
+		resp.SetBatchSize(c.BatchSize)
+	}
+
+	if resp.MaxTime == nil && c.MaxQueryTime > 0 {
+		resp.SetMaxTime(c.MaxQueryTime)
+	}
+
+	if resp.Comment == nil && c.Comment != "" {
+		resp.SetComment(c.Comment)
+	}
+
+	return resp
+}
+
+// findOneOptions merges the caller's find one options and fills in the collection defaults they leave unset
+func (c *DatabaseCollection) findOneOptions(opts ...*options.FindOneOptions) *options.FindOneOptions {
+	resp := options.MergeFindOneOptions(opts...)
+
+	if resp.MaxTime == nil && c.MaxQueryTime > 0 {
+		resp.SetMaxTime(c.MaxQueryTime)
+	}
+
+	if resp.Comment == nil && c.Comment != "" {
+		resp.SetComment(c.Comment)
+	}
+
+	return resp
+}
+
+// aggregateOptions merges the caller's aggregate options and fills in the collection defaults they leave unset
+func (c *DatabaseCollection) aggregateOptions(opts ...*options.AggregateOptions) *options.AggregateOptions {
+	resp := options.MergeAggregateOptions(opts...)
+
+	if resp.Comment == nil && c.Comment != "" {
+		resp.SetComment(c.Comment)
+	}
+
+	return resp
+}
+
+// GetItems returns a cursor over every document matching the filter
+func (c *DatabaseCollection) GetItems(ctx context.Context, filter bson.D, opts ...*options.FindOptions) (_ *mongo.Cursor, err error) {
+	defer c.observe("GetItems", time.Now(), &err)
+
+	return c.getItems(ctx, filter, opts...)
+}
+
+// getItems is GetItems for the observed operations built on it, so their failures are counted once
+func (c *DatabaseCollection) getItems(ctx context.Context, filter bson.D, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	cursor, err := c.collection.Find(ctx, c.liveFilter(filter), c.findOptions(opts...))
+	if fallback, ok := c.secondaryFallback(err); ok {
+		cursor, err = fallback.Find(ctx, c.liveFilter(filter), c.findOptions(opts...))
+	}
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return cursor, nil
+}
+
+// GetItemsMatching returns a cursor over the documents whose field contains pattern. The pattern is matched
+// literally with every regex metacharacter escaped, except that a leading ^ anchors it to the start of the value; a
+// case sensitive prefix match can use an index on the field while any other match scans it.
+func (c *DatabaseCollection) GetItemsMatching(ctx context.Context, field, pattern string, caseInsensitive bool) (*mongo.Cursor, error) {
+	expr := regexp.QuoteMeta(pattern)
+	if strings.HasPrefix(pattern, "^") {
+		expr = "^" + regexp.QuoteMeta(pattern[1:])
+	}
+
+	var flags string
+	if caseInsensitive {
+		flags = "i"
+	}
+
+	return c.GetItems(ctx, bson.D{{Key: field, Value: primitive.Regex{Pattern: expr, Options: flags}}})
+}
+
+// CountItems returns the number of documents matching the filter
+func (c *DatabaseCollection) CountItems(ctx context.Context, filter bson.D) (_ int64, err error) {
+	defer c.observe("CountItems", time.Now(), &err)
+
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	count, err := c.collection.CountDocuments(ctx, c.liveFilter(filter))
+	if fallback, ok := c.secondaryFallback(err); ok {
+		count, err = fallback.CountDocuments(ctx, c.liveFilter(filter))
+	}
+	if err != nil {
+		return 0, operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return count, nil
+}
+
+// GetAllInto decodes every document matching the filter into dest, which must be a pointer to a slice. When maxDocs is
+// greater than zero the query fails with ErrorTooManyDocuments rather than loading more than maxDocs documents.
+func (c *DatabaseCollection) GetAllInto(ctx context.Context, filter bson.D, dest interface{}, maxDocs int64) (err error) {
+	defer c.observe("GetAllInto", time.Now(), &err)
+
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return ErrorValueNotSlice
+	}
+
+	opts := options.Find()
+	if maxDocs > 0 {
+		// One document past the cap is enough to know it was exceeded
+		opts.SetLimit(maxDocs + 1)
+	}
+
+	cursor, err := c.getItems(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	slice := rv.Elem()
+	items := reflect.MakeSlice(slice.Type(), 0, 0)
+	for cursor.Next(ctx) {
+		if maxDocs > 0 && int64(items.Len()) >= maxDocs {
+			return ErrorTooManyDocuments
+		}
+
+		item := reflect.New(slice.Type().Elem())
+		if err := cursor.Decode(item.Interface()); err != nil {
+			return ErrorDecodeFailed
+		}
+		items = reflect.Append(items, item.Elem())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return operationError(ctx, err, ErrorGetFailed)
+	}
+
+	slice.Set(items)
+	return nil
+}
+
+// GetItemsAsMap decodes the documents matching the filter into dest, a pointer to a map[string]T, keyed by the value
+// of keyField, which must be a string or an ObjectID in every document. When several documents share a key the last
+// one read is kept.
+func (c *DatabaseCollection) GetItemsAsMap(ctx context.Context, filter bson.D, keyField string, dest interface{}) (err error) {
+	defer c.observe("GetItemsAsMap", time.Now(), &err)
+
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Map || rv.Elem().Type().Key().Kind() != reflect.String {
+		return ErrorValueNotMap
+	}
+
+	cursor, err := c.getItems(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	mapType := rv.Elem().Type()
+	items := reflect.MakeMap(mapType)
+	for cursor.Next(ctx) {
+		value := cursor.Current.Lookup(strings.Split(keyField, ".")...)
+
+		key, ok := value.StringValueOK()
+		if id, isID := value.ObjectIDOK(); isID {
+			key, ok = id.Hex(), true
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s is a %s", ErrorMapKeyInvalid, keyField, value.Type)
+		}
+
+		item := reflect.New(mapType.Elem())
+		if err := cursor.Decode(item.Interface()); err != nil {
+			return ErrorDecodeFailed
+		}
+		items.SetMapIndex(reflect.ValueOf(key).Convert(mapType.Key()), item.Elem())
+	}
+
+	if err := cursor.Err(); err != nil {
+		return operationError(ctx, err, ErrorGetFailed)
+	}
+
+	rv.Elem().Set(items)
+	return nil
+}
+
+// GetItemsByIDsOrdered decodes the documents with the ids into dest, a pointer to a slice, in the order of ids. The
+// slice has one element per id, and ids without a document get the element's zero value, such as nil for a slice of
+// pointers, which is the alignment a dataloader batch expects.
+func (c *DatabaseCollection) GetItemsByIDsOrdered(ctx context.Context, ids []primitive.ObjectID, dest interface{}) (err error) {
+	defer c.observe("GetItemsByIDsOrdered", time.Now(), &err)
+
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return ErrorValueNotSlice
+	}
+
+	slice := rv.Elem()
+	items := reflect.MakeSlice(slice.Type(), len(ids), len(ids))
+
+	if len(ids) == 0 {
+		slice.Set(items)
+		return nil
+	}
+
+	cursor, err := c.getItems(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	found := make(map[primitive.ObjectID]reflect.Value, len(ids))
+	for cursor.Next(ctx) {
+		id, ok := cursor.Current.Lookup("_id").ObjectIDOK()
+		if !ok {
+			continue
+		}
+
+		item := reflect.New(slice.Type().Elem())
+		if err := cursor.Decode(item.Interface()); err != nil {
+			return ErrorDecodeFailed
+		}
+		found[id] = item.Elem()
+	}
+
+	if err := cursor.Err(); err != nil {
+		return operationError(ctx, err, ErrorGetFailed)
+	}
+
+	for n, id := range ids {
+		if item, ok := found[id]; ok {
+			items.Index(n).Set(item)
+		}
+	}
+
+	slice.Set(items)
+	return nil
+}
+
+// GetItemsSorted returns a cursor over the documents matching the filter in the given order. A non-nil collation
+// applies locale-aware comparison rules, such as case-insensitive ordering, instead of byte order.
+func (c *DatabaseCollection) GetItemsSorted(ctx context.Context, filter bson.D, sort bson.D, collation *options.Collation) (*mongo.Cursor, error) {
+	opts := options.Find().SetSort(sort)
+	if collation != nil {
+		opts.SetCollation(collation)
+	}
+
+	return c.GetItems(ctx, filter, opts)
+}
+
+// GetItemsBatched returns a cursor over the documents matching the filter, fetching batchSize documents per round
+// trip
+func (c *DatabaseCollection) GetItemsBatched(ctx context.Context, filter bson.D, batchSize int32) (*mongo.Cursor, error) {
+	if batchSize <= 0 {
+		return nil, ErrorBatchSizeInvalid
+	}
+
+	return c.GetItems(ctx, filter, options.Find().SetBatchSize(batchSize))
+}
+
+// GetItemsWithHint returns a cursor over the documents matching the filter, forcing the query planner to use the
+// named index
+func (c *DatabaseCollection) GetItemsWithHint(ctx context.Context, filter bson.D, indexName string) (*mongo.Cursor, error) {
+	return c.GetItems(ctx, filter, options.Find().SetHint(indexName))
+}
+
+// Tail delivers documents matching the filter to fn as they are inserted into a capped collection, blocking until the
+// context is cancelled, fn returns an error or the query fails. When the cursor dies, for example because the
+// collection was empty or the server killed it when the capped collection wrapped, it is reopened after the last
+// delivered _id so documents are not delivered twice. Resuming relies on _id increasing in insertion order; documents
+// inserted with a lower _id than the last delivered one, such as ObjectIDs from hosts with skewed clocks or ids from
+// an IDGenerator that is not monotonic, are skipped.
+func (c *DatabaseCollection) Tail(ctx context.Context, filter bson.D, fn func(bson.Raw) error) error {
+	if filter == nil {
+		filter = bson.D{}
+	}
+
+	opts := options.Find().SetCursorType(options.TailableAwait)
+
+	var lastID *bson.RawValue
+	for {
+		query := filter
+		if lastID != nil {
+			query = bson.D{{Key: "$and", Value: bson.A{filter, bson.D{{Key: "_id", Value: bson.D{{Key: "$gt", Value: *lastID}}}}}}}
+		}
+
+		cursor, err := c.collection.Find(ctx, query, opts)
+		if err != nil {
+			return c.tailError(ctx, err)
+		}
+
+		for cursor.Next(ctx) {
+			if err := fn(cursor.Current); err != nil {
+				cursor.Close(context.Background())
+				return err
+			}
+
+			// The cursor reuses its buffer, so keep a copy of the id to resume from
+			id := cursor.Current.Lookup("_id")
+			lastID = &bson.RawValue{Type: id.Type, Value: append([]byte(nil), id.Value...)}
+		}
+
+		err = cursor.Err()
+		cursor.Close(context.Background())
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !isCursorLostError(err) {
+			return c.tailError(ctx, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(tailRetryInterval):
+		}
+	}
+}
+
+// tailError maps and counts a query failure that ends Tail, which is not observed since it runs until cancelled
+func (c *DatabaseCollection) tailError(ctx context.Context, err error) error {
+	resp := operationError(ctx, err, ErrorGetFailed)
+	countError(resp)
+	return resp
+}
+
+// Stream delivers every document matching the filter on the returned channel from a background goroutine. Both
+// channels are closed once the results are exhausted, the query fails or the context is cancelled; a failure is sent
+// on the error channel before it closes.
+func (c *DatabaseCollection) Stream(ctx context.Context, filter bson.D) (<-chan bson.Raw, <-chan error) {
+	docs := make(chan bson.Raw)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(docs)
+
+		cursor, err := c.GetItems(ctx, filter)
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer cursor.Close(context.Background())
+
+		for cursor.Next(ctx) {
+			// The cursor reuses its buffer, so each document sent needs its own copy
+			doc := append(bson.Raw(nil), cursor.Current...)
+
+			select {
+			case docs <- doc:
+			case <-ctx.Done():
+				err := operationError(ctx, ctx.Err(), ctx.Err())
+				countError(err)
+				errs <- err
+				return
+			}
+		}
+
+		if err := cursor.Err(); err != nil {
+			err = operationError(ctx, err, ErrorGetFailed)
+			countError(err)
+			errs <- err
+		}
+	}()
+
+	return docs, errs
+}
+
+// StreamJSON writes every document matching the filter to w as a JSON array of relaxed extended JSON, one document at a
+// time so memory use stays constant. Writers that can flush, such as an http.ResponseWriter, are flushed after each
+// document.
+func (c *DatabaseCollection) StreamJSON(ctx context.Context, filter bson.D, w io.Writer) (err error) {
+	defer c.observe("StreamJSON", time.Now(), &err)
+
+	cursor, err := c.getItems(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	flusher, _ := w.(interface{ Flush() })
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	for first := true; cursor.Next(ctx); first = false {
+		doc, err := bson.MarshalExtJSON(cursor.Current, false, false)
+		if err != nil {
+			return ErrorDecodeFailed
+		}
+
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+
+		if _, err := w.Write(doc); err != nil {
+			return err
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return operationError(ctx, err, ErrorGetFailed)
+	}
+
+	_, err = io.WriteString(w, "]")
+	return err
+}
+
+// CopyTo streams the documents matching the filter into dest, inserting batchSize documents per round trip, and
+// returns how many were copied. On cancellation or failure the documents copied so far are counted and kept.
+func (c *DatabaseCollection) CopyTo(ctx context.Context, dest *DatabaseCollection, filter bson.D, batchSize int) (_ int64, err error) {
+	defer c.observe("CopyTo", time.Now(), &err)
+
+	if batchSize <= 0 {
+		return 0, ErrorBatchSizeInvalid
+	}
+
+	cursor, err := c.getItems(ctx, filter, options.Find().SetBatchSize(int32(batchSize)))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.Background())
+
+	var copied int64
+	batch := make([]interface{}, 0, batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		result, err := dest.NewItems(ctx, batch)
+		if result != nil {
+			copied += int64(len(result.InsertedIDs))
+		}
+		if err != nil {
+			return err
+		}
+
+		batch = batch[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		// The cursor reuses its buffer, so each buffered document needs its own copy
+		batch = append(batch, append(bson.Raw(nil), cursor.Current...))
+
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return copied, err
+			}
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return copied, operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return copied, flush()
+}
+
+// Watch opens a change stream over the collection filtered by the pipeline
+func (c *DatabaseCollection) Watch(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.ChangeStreamOptions) (_ *mongo.ChangeStream, err error) {
+	defer c.observe("Watch", time.Now(), &err)
+
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+
+	stream, err := c.collection.Watch(ctx, pipeline, opts...)
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return stream, nil
+}
+
+// Aggregate returns a cursor over the results of the pipeline. A pipeline with an $out or $merge stage writes to a
+// collection, so it returns ErrorReadOnly on a ReadOnly collection.
+func (c *DatabaseCollection) Aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (_ *mongo.Cursor, err error) {
+	defer c.observe("Aggregate", time.Now(), &err)
+
+	return c.aggregate(ctx, pipeline, opts...)
+}
+
+// aggregate is Aggregate for the observed operations built on it, so their failures are counted once
+func (c *DatabaseCollection) aggregate(ctx context.Context, pipeline mongo.Pipeline, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	if c.ReadOnly && writesOutput(pipeline) {
+		return nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	cursor, err := c.collection.Aggregate(ctx, pipeline, c.aggregateOptions(opts...))
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorAggregateFailed)
+	}
+
+	return cursor, nil
+}
+
+// writesOutput reports whether the pipeline has a stage that writes its results to a collection
+func writesOutput(pipeline mongo.Pipeline) bool {
+	for _, stage := range pipeline {
+		for _, e := range stage {
+			if e.Key == "$out" || e.Key == "$merge" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Sample returns a cursor over n random documents matching the filter, or every matching document when there are
+// fewer than n
+func (c *DatabaseCollection) Sample(ctx context.Context, n int64, filter bson.D) (*mongo.Cursor, error) {
+	if n <= 0 {
+		return nil, ErrorSampleSizeInvalid
+	}
+	if filter == nil {
+		filter = bson.D{}
+	}
+
+	return c.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: c.liveFilter(filter)}},
+		{{Key: "$sample", Value: bson.D{{Key: "size", Value: n}}}},
+	})
+}
+
+// FindOrphans returns a cursor over the documents whose refField is set but matches no otherKeyField in the other
+// collection, such as orders whose user_id has no user. Both collections must be in the same database, and an index
+// on otherKeyField keeps the lookup from scanning the other collection once per document.
+func (c *DatabaseCollection) FindOrphans(ctx context.Context, refField string, other *DatabaseCollection, otherKeyField string) (*mongo.Cursor, error) {
+	const matches = "_orphan_matches"
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: c.liveFilter(bson.D{{Key: refField, Value: bson.D{{Key: "$ne", Value: nil}}}})}},
+		{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: other.name},
+			{Key: "localField", Value: refField},
+			{Key: "foreignField", Value: otherKeyField},
+			{Key: "as", Value: matches},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: matches, Value: bson.D{{Key: "$size", Value: 0}}}}}},
+		{{Key: "$project", Value: bson.D{{Key: matches, Value: 0}}}},
+	}
+
+	return c.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+}
+
+// GetComputed returns the first document matching the filter shaped by a $project stage, so the projection can
+// compute fields with aggregation expressions, such as {"total": {"$sum": "$lines.price"}}. It returns ErrorNotFound
+// when nothing matches.
+func (c *DatabaseCollection) GetComputed(ctx context.Context, filter bson.D, projection bson.D) (_ *mongo.SingleResult, err error) {
+	defer c.observe("GetComputed", time.Now(), &err)
+
+	if filter == nil {
+		filter = bson.D{}
+	}
+
+	cursor, err := c.aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: c.liveFilter(filter)}},
+		{{Key: "$limit", Value: 1}},
+		{{Key: "$project", Value: projection}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	if !cursor.Next(ctx) {
+		if err := cursor.Err(); err != nil {
+			return nil, operationError(ctx, err, ErrorAggregateFailed)
+		}
+		return nil, notFound(ErrorAggregateFailed)
+	}
+
+	return mongo.NewSingleResultFromDocument(append(bson.Raw(nil), cursor.Current...), nil, c.registry), nil
+}
+
+// AggregateInto runs the pipeline and decodes every result into dest, which must be a pointer to a slice
+func (c *DatabaseCollection) AggregateInto(ctx context.Context, pipeline mongo.Pipeline, dest interface{}) (err error) {
+	defer c.observe("AggregateInto", time.Now(), &err)
+
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return ErrorValueNotSlice
+	}
+
+	cursor, err := c.aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+
+	if err := cursor.All(ctx, dest); err != nil {
+		return ErrorDecodeFailed
+	}
+
+	return nil
+}
+
+// UpdateItem replaces the document with the item's ID and returns it as stored, or ErrorNotFound when there is no such
+// document, which also matches ErrorUpdateFailed and ErrorGetFailed
+func (c *DatabaseCollection) UpdateItem(ctx context.Context, i interface{}) (_ *mongo.SingleResult, err error) {
+	defer c.observe("UpdateItem", time.Now(), &err)
+
+	if c.ReadOnly {
+		return nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	idField, err := structID(i)
+	if err != nil {
+		return nil, err
+	}
+
+	if idField.IsZero() {
+		return nil, ErrorIdBlank
+	}
+	id := idField.Interface()
+
+	shard, err := c.shardKeyFilter(i)
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.lockID(id)()
+
+	filter := append(bson.D{{Key: "_id", Value: id}}, shard...)
+
+	var result *mongo.UpdateResult
+	err = c.retryWrite(ctx, func() (err error) {
+		result, err = c.collection.ReplaceOne(ctx, filter, i)
+		return err
+	})
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorUpdateFailed)
+	}
+
+	if result.MatchedCount == 0 {
+		// A miss was reported as ErrorGetFailed by the read back before UpdateItem checked the match
+		return nil, notFound(ErrorUpdateFailed, ErrorGetFailed)
+	}
+
+	return c.getByID(ctx, id)
+}
+
+// UpsertItem replaces the document with the item's ID, inserting it when it does not exist. When the ID is blank it
+// is taken from IDGenerator, or else generated by Mongo, and written back into the item so the caller can reference
+// the new document.
+func (c *DatabaseCollection) UpsertItem(ctx context.Context, i interface{}) (_ *mongo.SingleResult, err error) {
+	defer c.observe("UpsertItem", time.Now(), &err)
+
+	if c.ReadOnly {
+		return nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	idField, err := structID(i)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only Mongo generates ObjectIDs for a blank id, other types need an id from IDGenerator to fit the ID field
+	blank := idField.IsZero()
+	if blank && c.IDGenerator != nil {
+		if err := c.generateID(idField); err != nil {
+			return nil, err
+		}
+		blank = false
+	}
+	id := idField.Interface()
+
+	// The shard key comes from the item whatever its id, so a sharded collection can route the upsert either way
+	shard, err := c.shardKeyFilter(i)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter, replacement interface{} = append(bson.D{{Key: "_id", Value: id}}, shard...), i
+	if !blank {
+		defer c.lockID(id)()
+	} else {
+		// Match nothing by _id so the upsert always inserts, and leave the zero id out of the document
+		filter = append(bson.D{{Key: "_id", Value: bson.D{{Key: "$exists", Value: false}}}}, shard...)
+
+		replacement, err = c.withoutID(i)
+		if err != nil {
+			return nil, ErrorUpdateFailed
+		}
+	}
+
+	var result *mongo.UpdateResult
+	upsert := func() (err error) {
+		result, err = c.collection.ReplaceOne(ctx, filter, replacement, options.Replace().SetUpsert(true))
+		return err
+	}
+
+	// A blank id never matches, so a duplicate key is on another unique index and would fail again
+	if !blank {
+		err = retryDuplicateUpsert(upsert)
+	} else {
+		err = upsert()
+	}
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorUpdateFailed)
+	}
+
+	if blank && result.UpsertedID != nil {
+		upserted := reflect.ValueOf(result.UpsertedID)
+		if idField.CanSet() && upserted.Type().AssignableTo(idField.Type()) {
+			idField.Set(upserted)
+		}
+		id = result.UpsertedID
+	}
+
+	return c.getByID(ctx, id)
+}
+
+// UpdatePartialFromStruct sets only the non-zero fields of i on the document with the id, as built by
+// BuildSetFromStruct, and returns the updated document
+func (c *DatabaseCollection) UpdatePartialFromStruct(ctx context.Context, id primitive.ObjectID, i interface{}) (_ *mongo.SingleResult, err error) {
+	defer c.observe("UpdatePartialFromStruct", time.Now(), &err)
+
+	if c.ReadOnly {
+		return nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	set := BuildSetFromStruct(i)
+	if len(set) == 0 {
+		return c.getByID(ctx, id)
+	}
+
+	defer c.lockID(id)()
+
+	_, err = c.collection.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, bson.D{{Key: "$set", Value: set}})
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorUpdateFailed)
+	}
+
+	return c.getByID(ctx, id)
+}
+
+// UpdateArrayElement applies update to the document with the ID, matching the $[<identifier>] placeholders in it
+// against arrayFilters, such as bson.M{"item.sku": "abc"} for "lines.$[item].qty"
+func (c *DatabaseCollection) UpdateArrayElement(ctx context.Context, id primitive.ObjectID, update bson.M, arrayFilters []interface{}) (_ *mongo.UpdateResult, err error) {
+	defer c.observe("UpdateArrayElement", time.Now(), &err)
+
+	if c.ReadOnly {
+		return nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	defer c.lockID(id)()
+
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+
+	result, err := c.collection.UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, update, opts)
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorUpdateFailed)
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, notFound(ErrorUpdateFailed)
+	}
+
+	return result, nil
+}
+
+// FindOneAndReplace atomically replaces the first document matching the filter and returns it, before the
+// replacement by default or after it with SetReturnDocument(options.After)
+func (c *DatabaseCollection) FindOneAndReplace(ctx context.Context, filter bson.D, replacement interface{}, opts ...*options.FindOneAndReplaceOptions) *mongo.SingleResult {
+	defer c.observe("FindOneAndReplace", time.Now(), nil)
+
+	if c.ReadOnly {
+		return mongo.NewSingleResultFromDocument(bson.D{}, ErrorReadOnly, c.registry)
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	return c.collection.FindOneAndReplace(ctx, filter, replacement, opts...)
+}
+
+// UpsertWithDefaults updates the document matching the filter with set, creating it when missing. The fields in
+// setOnInsert are only written when the document is created, such as a created_at timestamp.
+func (c *DatabaseCollection) UpsertWithDefaults(ctx context.Context, filter bson.D, set bson.M, setOnInsert bson.M) (_ *mongo.UpdateResult, err error) {
+	defer c.observe("UpsertWithDefaults", time.Now(), &err)
+
+	if c.ReadOnly {
+		return nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	update := bson.D{}
+	if len(set) > 0 {
+		update = append(update, bson.E{Key: "$set", Value: set})
+	}
+	if len(setOnInsert) > 0 {
+		update = append(update, bson.E{Key: "$setOnInsert", Value: setOnInsert})
+	}
+
+	var result *mongo.UpdateResult
+	err = retryDuplicateUpsert(func() (err error) {
+		result, err = c.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+		return err
+	})
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorUpdateFailed)
+	}
+
+	return result, nil
+}
+
+// IncrementWithinWindow counts a hit against the key in a fixed window of the given length and reports whether the
+// count is still within the limit, as a rate limiter shared by every instance of a service. The window starts at the
+// first hit after the previous one has ended and is timed by the server clock, so it is atomic across callers. The
+// window's end is kept in expiresAt, which a TTL index with zero expireAfterSeconds can use to clear idle keys.
+func (c *DatabaseCollection) IncrementWithinWindow(ctx context.Context, key string, window time.Duration, limit int64) (allowed bool, count int64, err error) {
+	defer c.observe("IncrementWithinWindow", time.Now(), &err)
+
+	if c.ReadOnly {
+		return false, 0, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	ms := window.Milliseconds()
+	inWindow := bson.D{{Key: "$gt", Value: bson.A{"$windowStart", bson.D{{Key: "$subtract", Value: bson.A{"$$NOW", ms}}}}}}
+
+	// Every expression reads the document as it was before the update, so all three agree on whether it is in window
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.D{
+		{Key: "count", Value: bson.D{{Key: "$cond", Value: bson.A{inWindow, bson.D{{Key: "$add", Value: bson.A{"$count", 1}}}, 1}}}},
+		{Key: "windowStart", Value: bson.D{{Key: "$cond", Value: bson.A{inWindow, "$windowStart", "$$NOW"}}}},
+		{Key: "expiresAt", Value: bson.D{{Key: "$cond", Value: bson.A{inWindow,
+			bson.D{{Key: "$add", Value: bson.A{"$windowStart", ms}}},
+			bson.D{{Key: "$add", Value: bson.A{"$$NOW", ms}}},
+		}}}},
+	}}}}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var resp struct {
+		Count int64 `bson:"count"`
+	}
+	err = retryDuplicateUpsert(func() error {
+		return c.collection.FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: key}}, update, opts).Decode(&resp)
+	})
+	if err != nil {
+		return false, 0, operationError(ctx, err, ErrorUpdateFailed)
+	}
+
+	return resp.Count <= limit, resp.Count, nil
+}
+
+// ClaimNext atomically applies claimUpdate to the first document matching the filter in sort order and returns the
+// updated document, so competing workers never claim the same job. A nil result and nil error means nothing matched.
+func (c *DatabaseCollection) ClaimNext(ctx context.Context, filter bson.D, claimUpdate bson.M, sort bson.D) (_ *mongo.SingleResult, err error) {
+	defer c.observe("ClaimNext", time.Now(), &err)
+
+	if c.ReadOnly {
+		return nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetSort(sort).SetReturnDocument(options.After)
+
+	item := c.collection.FindOneAndUpdate(ctx, filter, claimUpdate, opts)
+	if err := item.Err(); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, operationError(ctx, err, ErrorUpdateFailed)
+	}
+
+	return item, nil
+}
+
+// DeleteItem removes the document with the id, returning ErrorNotFound when there is none. The context is passed to
+// the driver, so a delete made with a session context takes part in its transaction.
+func (c *DatabaseCollection) DeleteItem(ctx context.Context, id primitive.ObjectID) (err error) {
+	defer c.observe("DeleteItem", time.Now(), &err)
+
+	if c.ReadOnly {
+		return ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, deleteOperation)
+	defer cancel()
+
+	return c.deleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+}
+
+// DeleteItemFromStruct removes the document with the item's ID, returning ErrorNotFound when there is none. Unlike
+// DeleteItem the filter also carries the item's ShardKey fields, so the delete is routed to a single shard.
+func (c *DatabaseCollection) DeleteItemFromStruct(ctx context.Context, i interface{}) (err error) {
+	defer c.observe("DeleteItemFromStruct", time.Now(), &err)
+
+	if c.ReadOnly {
+		return ErrorReadOnly
+	}
+
+	idField, err := structID(i)
+	if err != nil {
+		return err
+	}
+
+	if idField.IsZero() {
+		return ErrorIdBlank
+	}
+	id := idField.Interface()
+
+	shard, err := c.shardKeyFilter(i)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.withTimeout(ctx, deleteOperation)
+	defer cancel()
+
+	return c.deleteOne(ctx, append(bson.D{{Key: "_id", Value: id}}, shard...))
+}
+
+func (c *DatabaseCollection) deleteOne(ctx context.Context, filter bson.D) error {
+	var result *mongo.DeleteResult
+	err := c.retryWrite(ctx, func() (err error) {
+		result, err = c.collection.DeleteOne(ctx, filter)
+		return err
+	})
+	if err != nil {
+		return operationError(ctx, err, ErrorDeleteFailed)
+	}
+
+	if result.DeletedCount == 0 {
+		return notFound(ErrorDeleteFailed)
+	}
+
+	return nil
+}
+
+// SoftDeleteItem marks the document with the ID as deleted in SoftDeleteField, hiding it from reads while keeping it
+// in the collection. It returns ErrorNotFound when no live document has the ID.
+func (c *DatabaseCollection) SoftDeleteItem(ctx context.Context, id primitive.ObjectID) (err error) {
+	defer c.observe("SoftDeleteItem", time.Now(), &err)
+
+	if c.ReadOnly {
+		return ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, deleteOperation)
+	defer cancel()
+
+	if c.SoftDeleteField == "" {
+		return ErrorSoftDeleteDisabled
+	}
+
+	var mark interface{} = time.Now().UTC()
+	if c.SoftDeleteFlag {
+		mark = true
+	}
+
+	defer c.lockID(id)()
+
+	filter := c.liveFilter(bson.D{{Key: "_id", Value: id}})
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: c.SoftDeleteField, Value: mark}}}}
+
+	result, err := c.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return operationError(ctx, err, ErrorDeleteFailed)
+	}
+
+	if result.MatchedCount == 0 {
+		return notFound(ErrorDeleteFailed)
+	}
+
+	return nil
+}
+
+// DeleteItemsReturning deletes every document matching the filter and returns the deleted documents, for example to
+// write audit records. The find and the delete run in one transaction unless the context already carries a session or
+// NonAtomicDeletes is set. Without a transaction only the returned documents are deleted, but one may have been
+// modified between being read and being deleted.
+func (c *DatabaseCollection) DeleteItemsReturning(ctx context.Context, filter bson.D) (_ []bson.Raw, err error) {
+	defer c.observe("DeleteItemsReturning", time.Now(), &err)
+
+	if c.ReadOnly {
+		return nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, deleteOperation)
+	defer cancel()
+
+	if c.NonAtomicDeletes || mongo.SessionFromContext(ctx) != nil {
+		docs, err := c.deleteReturning(ctx, filter)
+		if err != nil {
+			return nil, operationError(ctx, err, ErrorDeleteFailed)
+		}
+		return docs, nil
+	}
+
+	session, err := c.collection.Database().Client().StartSession()
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorDeleteFailed)
+	}
+	defer session.EndSession(ctx)
+
+	// The driver errors are returned as they are so that WithTransaction sees their labels and retries a transient
+	// failure such as a write conflict
+	resp, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return c.deleteReturning(sc, filter)
+	})
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorDeleteFailed)
+	}
+
+	return resp.([]bson.Raw), nil
+}
+
+// deleteReturning reads and then deletes the documents matching the filter, returning the driver's errors unmapped
+func (c *DatabaseCollection) deleteReturning(ctx context.Context, filter bson.D) ([]bson.Raw, error) {
+	cursor, err := c.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []bson.Raw
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	if len(docs) == 0 {
+		return docs, nil
+	}
+
+	// Delete exactly what was read, so documents matching the filter after the find are left alone
+	ids := make(bson.A, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.Lookup("_id"))
+	}
+
+	_, err = c.collection.DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	if err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
+
+// TruncateCollection deletes every document in the collection and returns how many were removed. It does nothing
+// unless confirm is true, so that an empty filter delete cannot happen by accident.
+func (c *DatabaseCollection) TruncateCollection(ctx context.Context, confirm bool) (_ int64, err error) {
+	defer c.observe("TruncateCollection", time.Now(), &err)
+
+	if c.ReadOnly {
+		return 0, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, deleteOperation)
+	defer cancel()
+
+	if !confirm {
+		return 0, ErrorConfirmationRequired
+	}
+
+	result, err := c.collection.DeleteMany(ctx, bson.D{})
+	if err != nil {
+		return 0, operationError(ctx, err, ErrorDeleteFailed)
+	}
+
+	return result.DeletedCount, nil
+}
+
+// DeleteOlderThan removes every document whose field is older than age, deleting at most batchSize documents per
+// round trip so that long retention runs do not hold locks for the whole operation. Timeouts.Delete bounds each
+// round trip rather than the whole run. It returns the total number of documents deleted, including those removed
+// before a cancellation or failure.
+func (c *DatabaseCollection) DeleteOlderThan(ctx context.Context, field string, age time.Duration, batchSize int64) (_ int64, err error) {
+	defer c.observe("DeleteOlderThan", time.Now(), &err)
+
+	if c.ReadOnly {
+		return 0, ErrorReadOnly
+	}
+
+	if batchSize <= 0 {
+		return 0, ErrorBatchSizeInvalid
+	}
+
+	filter := bson.D{{Key: field, Value: bson.D{{Key: "$lt", Value: time.Now().Add(-age)}}}}
+	opts := options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}).SetLimit(batchSize)
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, operationError(ctx, err, err)
+		}
+
+		ids, err := c.findIDs(ctx, filter, opts)
+		if err != nil {
+			return total, err
+		}
+
+		if len(ids) == 0 {
+			return total, nil
+		}
+
+		deleted, err := c.deleteIDs(ctx, ids)
+		if err != nil {
+			return total, err
+		}
+
+		total += deleted
+	}
+}
+
+// findIDs returns the _id of the documents matching the filter in one round trip bounded by Timeouts.Delete
+func (c *DatabaseCollection) findIDs(ctx context.Context, filter bson.D, opts *options.FindOptions) (bson.A, error) {
+	ctx, cancel := c.withTimeout(ctx, deleteOperation)
+	defer cancel()
+
+	cursor, err := c.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, operationError(ctx, err, ErrorDeleteFailed)
+	}
+
+	var batch []struct {
+		ID interface{} `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &batch); err != nil {
+		return nil, operationError(ctx, err, ErrorDeleteFailed)
+	}
+
+	ids := make(bson.A, 0, len(batch))
+	for i := range batch {
+		ids = append(ids, batch[i].ID)
+	}
+
+	return ids, nil
+}
+
+// deleteIDs removes the documents with the ids in one round trip bounded by Timeouts.Delete
+func (c *DatabaseCollection) deleteIDs(ctx context.Context, ids bson.A) (int64, error) {
+	ctx, cancel := c.withTimeout(ctx, deleteOperation)
+	defer cancel()
+
+	result, err := c.collection.DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	if err != nil {
+		return 0, operationError(ctx, err, ErrorDeleteFailed)
+	}
+
+	return result.DeletedCount, nil
+}
+
+// EnsureUniqueIndex creates a unique index on the keys, doing nothing when an identical index already exists
+func (c *DatabaseCollection) EnsureUniqueIndex(ctx context.Context, keys bson.D) error {
+	return c.ensureIndex(ctx, mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetUnique(true),
+	})
+}
+
+// EnsurePartialUniqueIndex creates a unique index on the keys that only applies to documents matching partialFilter,
+// for example to enforce unique emails among users that have not been soft deleted
+func (c *DatabaseCollection) EnsurePartialUniqueIndex(ctx context.Context, keys bson.D, partialFilter bson.D) error {
+	return c.ensureIndex(ctx, mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(partialFilter),
+	})
+}
+
+func (c *DatabaseCollection) ensureIndex(ctx context.Context, model mongo.IndexModel) (err error) {
+	defer c.observe("EnsureIndex", time.Now(), &err)
+
+	if c.ReadOnly {
+		return ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	_, err = c.collection.Indexes().CreateOne(ctx, model)
+	if err != nil {
+		return operationError(ctx, err, ErrorIndexFailed)
+	}
+
+	return nil
+}
+
+// FieldCardinality returns the number of distinct values of the field, counted on the server so that the values
+// themselves are never loaded into memory
+func (c *DatabaseCollection) FieldCardinality(ctx context.Context, field string) (_ int64, err error) {
+	defer c.observe("FieldCardinality", time.Now(), &err)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$" + field}}}},
+		{{Key: "$count", Value: "count"}},
+	}
+
+	cursor, err := c.aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return 0, err
+	}
+
+	var resp []struct {
+		Count int64 `bson:"count"`
+	}
+	if err := cursor.All(ctx, &resp); err != nil {
+		return 0, operationError(ctx, err, ErrorAggregateFailed)
+	}
+
+	if len(resp) == 0 {
+		return 0, nil
+	}
+
+	return resp[0].Count, nil
+}
+
+// IsQueryIndexed explains a find with the filter and sort and reports whether the winning plan is served by an index,
+// meaning it neither scans the whole collection nor sorts in memory
+func (c *DatabaseCollection) IsQueryIndexed(ctx context.Context, filter bson.D, sort bson.D) (_ bool, err error) {
+	defer c.observe("IsQueryIndexed", time.Now(), &err)
+
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	find := bson.D{{Key: "find", Value: c.name}, {Key: "filter", Value: filter}}
+	if len(sort) > 0 {
+		find = append(find, bson.E{Key: "sort", Value: sort})
+	}
+
+	cmd := bson.D{{Key: "explain", Value: find}, {Key: "verbosity", Value: "queryPlanner"}}
+
+	raw, err := c.collection.Database().RunCommand(ctx, cmd).DecodeBytes()
+	if err != nil {
+		return false, operationError(ctx, err, ErrorExplainFailed)
+	}
+
+	plan, err := raw.LookupErr("queryPlanner", "winningPlan")
+	if err != nil {
+		return false, ErrorExplainFailed
+	}
+
+	return !planHasStage(plan.Document(), "COLLSCAN", "SORT"), nil
+}
+
+// planHasStage walks an explain plan and reports whether any of its stages is one of the given stages
+func planHasStage(plan bson.Raw, stages ...string) bool {
+	elems, _ := plan.Elements()
+
+	for _, e := range elems {
+		v := e.Value()
+
+		switch v.Type {
+		case bsontype.String:
+			if e.Key() != "stage" {
+				continue
+			}
+			for _, stage := range stages {
+				if v.StringValue() == stage {
+					return true
+				}
+			}
+		case bsontype.EmbeddedDocument, bsontype.Array:
+			if planHasStage(bson.Raw(v.Value), stages...) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (c *DatabaseCollection) MongoCollectionType() *mongo.Collection {
+	t := reflect.TypeOf(c.collection)
+	val := reflect.New(t)
+
+	return val.Interface().(*mongo.Collection)
+}
+
+// CollStats are a collection's storage statistics as reported by collStats, with sizes in bytes
+type CollStats struct {
+	Count          int64            `bson:"count"`
+	AvgObjSize     float64          `bson:"avgObjSize"`
+	Size           int64            `bson:"size"`
+	StorageSize    int64            `bson:"storageSize"`
+	TotalIndexSize int64            `bson:"totalIndexSize"`
+	IndexSizes     map[string]int64 `bson:"indexSizes"`
+}
+
+// CollectionStats returns the collection's document count, document and storage sizes and the size of each index
+func (c *DatabaseCollection) CollectionStats(ctx context.Context) (_ CollStats, err error) {
+	defer c.observe("CollectionStats", time.Now(), &err)
+
+	ctx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	var resp CollStats
+
+	err = c.collection.Database().RunCommand(ctx, bson.D{{Key: "collStats", Value: c.name}}).Decode(&resp)
+	if err != nil {
+		return CollStats{}, operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return resp, nil
 }
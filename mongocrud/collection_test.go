@@ -1 +1,122 @@
 package mongocrud_test
+
+import (
+	// Standard
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	// Internal
+	"github.com/Shift-Dev-Studio/mongo-crud/mongocrud"
+)
+
+type testItem struct {
+	ID   primitive.ObjectID `bson:"_id"`
+	Name string             `bson:"name"`
+}
+
+// testClient connects to the replica set in MONGOCRUD_TEST_URI using a throwaway database with the named collections
+// registered, skipping the test when no server is configured
+func testClient(t *testing.T, collections ...string) *mongocrud.DatabaseClient {
+	t.Helper()
+
+	uri := os.Getenv("MONGOCRUD_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGOCRUD_TEST_URI is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	instance, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { instance.Disconnect(context.Background()) })
+
+	client := &mongocrud.DatabaseClient{
+		Instance: instance,
+		Database: instance.Database("mongocrud_test_" + primitive.NewObjectID().Hex()),
+	}
+	t.Cleanup(func() { client.Database.Drop(context.Background()) })
+
+	for _, name := range collections {
+		if err := client.Database.CreateCollection(ctx, name); err != nil {
+			t.Fatalf("create collection %s: %v", name, err)
+		}
+	}
+	client.AddCollections(ctx, client.MongoCollectionsToDatabaseCollections(ctx))
+
+	return client
+}
+
+func TestTransactionRollsBackInsertsAcrossCollections(t *testing.T) {
+	client := testClient(t, "first", "second")
+	ctx := context.Background()
+
+	first, second := client.GetCollection("first"), client.GetCollection("second")
+	a := &testItem{ID: primitive.NewObjectID(), Name: "a"}
+	b := &testItem{ID: primitive.NewObjectID(), Name: "b"}
+
+	err := client.Instance.UseSession(ctx, func(sc mongo.SessionContext) error {
+		if err := sc.StartTransaction(); err != nil {
+			return err
+		}
+
+		if _, err := first.NewItem(sc, a); err != nil {
+			return err
+		}
+		if _, err := second.NewItem(sc, b); err != nil {
+			return err
+		}
+
+		return sc.AbortTransaction(sc)
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+
+	if first.ItemExists(ctx, "id", a.ID.Hex()) {
+		t.Error("insert into first persisted after rollback")
+	}
+	if second.ItemExists(ctx, "id", b.ID.Hex()) {
+		t.Error("insert into second persisted after rollback")
+	}
+}
+
+func TestTransactionRollsBackDelete(t *testing.T) {
+	client := testClient(t, "first")
+	ctx := context.Background()
+
+	first := client.GetCollection("first")
+	a := &testItem{ID: primitive.NewObjectID(), Name: "a"}
+
+	if _, err := first.NewItem(ctx, a); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	err := client.Instance.UseSession(ctx, func(sc mongo.SessionContext) error {
+		if err := sc.StartTransaction(); err != nil {
+			return err
+		}
+
+		if err := first.DeleteItem(sc, a.ID); err != nil {
+			return err
+		}
+
+		return sc.AbortTransaction(sc)
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+
+	if !first.ItemExists(ctx, "id", a.ID.Hex()) {
+		t.Error("delete persisted after rollback")
+	}
+}
@@ -0,0 +1,68 @@
+package mongocrud
+
+import (
+	// Standard
+	"reflect"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DiffDocuments returns the fields that differ between two versions of a document, such as the before image of a
+// FindOneAndUpdate and the update, with their old and new values. Nested documents are compared field by field under
+// dotted keys, while arrays are compared whole. A field missing from one side has a nil value on that side.
+func DiffDocuments(before, after bson.M) map[string][2]interface{} {
+	resp := map[string][2]interface{}{}
+	diffDocuments("", before, after, resp)
+	return resp
+}
+
+func diffDocuments(prefix string, before, after map[string]interface{}, diff map[string][2]interface{}) {
+	for key, old := range before {
+		value, ok := after[key]
+		if !ok {
+			diff[prefix+key] = [2]interface{}{old, nil}
+			continue
+		}
+
+		diffValues(prefix+key, old, value, diff)
+	}
+
+	for key, value := range after {
+		if _, ok := before[key]; !ok {
+			diff[prefix+key] = [2]interface{}{nil, value}
+		}
+	}
+}
+
+func diffValues(key string, before, after interface{}, diff map[string][2]interface{}) {
+	b, bok := asDocument(before)
+	a, aok := asDocument(after)
+	if bok && aok {
+		diffDocuments(key+".", b, a, diff)
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		diff[key] = [2]interface{}{before, after}
+	}
+}
+
+// asDocument returns v as a map when it is a decoded embedded document
+func asDocument(v interface{}) (map[string]interface{}, bool) {
+	switch t := v.(type) {
+	case primitive.M:
+		return t, true
+	case map[string]interface{}:
+		return t, true
+	case primitive.D:
+		resp := make(map[string]interface{}, len(t))
+		for _, e := range t {
+			resp[e.Key] = e.Value
+		}
+		return resp, true
+	default:
+		return nil, false
+	}
+}
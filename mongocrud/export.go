@@ -0,0 +1,187 @@
+package mongocrud
+
+import (
+	// Standard
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Formats read and written by Export and Import
+const (
+	// FormatJSON is newline delimited canonical extended JSON, one document per line, which keeps every BSON type
+	FormatJSON = "json"
+	// FormatBSON is a concatenation of raw BSON documents, the format of mongodump's .bson files
+	FormatBSON = "bson"
+)
+
+// importBatchSize is the number of documents Import inserts per round trip
+const importBatchSize = 1000
+
+// maxImportDocSize bounds the length prefix Import accepts for a BSON document, the server's 16MiB limit, so a corrupt
+// file fails instead of allocating an arbitrary buffer
+const maxImportDocSize = 16 * 1024 * 1024
+
+// Export writes every document in the collection to w in the format, FormatJSON or FormatBSON, streaming them from
+// the cursor so memory use stays constant whatever the collection's size. Soft deleted documents are included.
+func (c *DatabaseCollection) Export(ctx context.Context, w io.Writer, format string) (err error) {
+	defer c.observe("Export", time.Now(), &err)
+
+	if format != FormatJSON && format != FormatBSON {
+		return fmt.Errorf("%w: %q", ErrorFormatInvalid, format)
+	}
+
+	// MaxQueryTime is left out since it would bound the whole export rather than a single query
+	opts := options.Find()
+	if c.BatchSize > 0 {
+		opts.SetBatchSize(c.BatchSize)
+	}
+
+	// The whole export runs on ctx, only opening the cursor is bounded by Timeouts.Read
+	findCtx, cancel := c.withTimeout(ctx, readOperation)
+	defer cancel()
+
+	cursor, err := c.collection.Find(findCtx, bson.D{}, opts)
+	if err != nil {
+		return operationError(findCtx, err, ErrorGetFailed)
+	}
+	defer cursor.Close(context.Background())
+
+	bw := bufio.NewWriter(w)
+	for cursor.Next(ctx) {
+		doc := []byte(cursor.Current)
+		if format == FormatJSON {
+			if doc, err = bson.MarshalExtJSON(cursor.Current, true, false); err != nil {
+				return ErrorDecodeFailed
+			}
+			doc = append(doc, '\n')
+		}
+
+		if _, err := bw.Write(doc); err != nil {
+			return err
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return operationError(ctx, err, ErrorGetFailed)
+	}
+
+	return bw.Flush()
+}
+
+// Import inserts the documents read from r in the format written by Export, in batches of importBatchSize, and
+// returns how many were inserted. Documents keep their _id, so importing into a collection that already holds them
+// fails with the duplicates.
+func (c *DatabaseCollection) Import(ctx context.Context, r io.Reader, format string) (_ int64, err error) {
+	defer c.observe("Import", time.Now(), &err)
+
+	var next func() (bson.Raw, error)
+	br := bufio.NewReader(r)
+
+	switch format {
+	case FormatJSON:
+		next = func() (bson.Raw, error) { return nextJSONDocument(br) }
+	case FormatBSON:
+		next = func() (bson.Raw, error) { return nextBSONDocument(br) }
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrorFormatInvalid, format)
+	}
+
+	var imported int64
+	batch := make([]interface{}, 0, importBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		result, err := c.newItems(ctx, batch)
+		if result != nil {
+			imported += int64(len(result.InsertedIDs))
+		}
+		if err != nil {
+			return err
+		}
+
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		doc, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return imported, err
+		}
+
+		batch = append(batch, doc)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return imported, err
+			}
+		}
+	}
+
+	return imported, flush()
+}
+
+// nextJSONDocument reads the next non-empty line of extended JSON, returning io.EOF once the input is exhausted
+func nextJSONDocument(r *bufio.Reader) (bson.Raw, error) {
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var doc bson.Raw
+			if err := bson.UnmarshalExtJSON(line, true, &doc); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrorDecodeFailed, err)
+			}
+			return doc, nil
+		}
+
+		if err != nil {
+			return nil, io.EOF
+		}
+	}
+}
+
+// nextBSONDocument reads the next length prefixed BSON document, returning io.EOF once the input is exhausted
+func nextBSONDocument(r *bufio.Reader) (bson.Raw, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("%w: %v", ErrorDecodeFailed, err)
+	}
+
+	size := int32(binary.LittleEndian.Uint32(prefix[:]))
+	if size < 5 || size > maxImportDocSize {
+		return nil, fmt.Errorf("%w: invalid document length %d", ErrorDecodeFailed, size)
+	}
+
+	doc := make([]byte, size)
+	copy(doc, prefix[:])
+	if _, err := io.ReadFull(r, doc[4:]); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrorDecodeFailed, err)
+	}
+
+	if err := bson.Raw(doc).Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrorDecodeFailed, err)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,67 @@
+package mongocrud
+
+import (
+	// Standard
+	"time"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Filters only match values of the same BSON type as the stored field, so a Go float64 will never equal a field stored
+// as a Decimal128 even when the numbers are the same; nor will a string equal an ObjectID. The helpers below build
+// filter elements already converted to the stored type.
+
+// DecimalFilter returns an equality filter element matching a field stored as a Decimal128, such as a monetary amount
+func DecimalFilter(field string, value string) (bson.E, error) {
+	d, err := primitive.ParseDecimal128(value)
+	if err != nil {
+		return bson.E{}, ErrorDecimalInvalid
+	}
+
+	return bson.E{Key: field, Value: d}, nil
+}
+
+// InFilter returns a filter element matching documents whose field is one of the values. With asObjectID the values
+// are parsed as hex ObjectIDs, and any that do not parse are left out since they could never match.
+func InFilter(field string, values []string, asObjectID bool) bson.E {
+	return bson.E{Key: field, Value: bson.D{{Key: "$in", Value: filterValues(values, asObjectID)}}}
+}
+
+// NinFilter is the counterpart of InFilter, matching documents whose field is none of the values
+func NinFilter(field string, values []string, asObjectID bool) bson.E {
+	return bson.E{Key: field, Value: bson.D{{Key: "$nin", Value: filterValues(values, asObjectID)}}}
+}
+
+func filterValues(values []string, asObjectID bool) bson.A {
+	resp := make(bson.A, 0, len(values))
+	for _, value := range values {
+		if !asObjectID {
+			resp = append(resp, value)
+		} else if id, err := ParseID(value); err == nil {
+			resp = append(resp, id)
+		}
+	}
+
+	return resp
+}
+
+// DateRangeFilter returns a filter element matching a date field from start, inclusive, up to end, exclusive. A zero
+// start or end leaves that side of the range open, and with both zero any document with the field matches.
+func DateRangeFilter(field string, start, end time.Time) bson.E {
+	bounds := bson.D{}
+	if !start.IsZero() {
+		bounds = append(bounds, bson.E{Key: "$gte", Value: start})
+	}
+	if !end.IsZero() {
+		bounds = append(bounds, bson.E{Key: "$lt", Value: end})
+	}
+
+	// An empty operator document would match the field against an empty document instead
+	if len(bounds) == 0 {
+		bounds = bson.D{{Key: "$exists", Value: true}}
+	}
+
+	return bson.E{Key: field, Value: bounds}
+}
@@ -0,0 +1,167 @@
+package mongocrud
+
+import (
+	// Standard
+	"bytes"
+	"context"
+	"time"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// EnsureIndexes creates the indexes in models that the collection does not have yet and returns the names of the
+// created and the already existing ones. An index exists when an index on the collection has the same key pattern
+// and the same unique, sparse, TTL and partial filter options. An index on the same keys with other options is left
+// for the server to reject, as replacing it would need a drop.
+func (c *DatabaseCollection) EnsureIndexes(ctx context.Context, models []mongo.IndexModel) (created, existing []string, err error) {
+	defer c.observe("EnsureIndexes", time.Now(), &err)
+
+	if c.ReadOnly {
+		return nil, nil, ErrorReadOnly
+	}
+
+	ctx, cancel := c.withTimeout(ctx, writeOperation)
+	defer cancel()
+
+	cursor, err := c.collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, nil, operationError(ctx, err, ErrorIndexFailed)
+	}
+
+	var current []bson.Raw
+	if err := cursor.All(ctx, &current); err != nil {
+		return nil, nil, operationError(ctx, err, ErrorIndexFailed)
+	}
+
+	var missing []mongo.IndexModel
+	for _, model := range models {
+		spec, err := indexSpec(model)
+		if err != nil {
+			return nil, nil, ErrorIndexFailed
+		}
+
+		name, ok := matchingIndex(current, spec)
+		if ok {
+			existing = append(existing, name)
+			continue
+		}
+
+		missing = append(missing, model)
+	}
+
+	if len(missing) == 0 {
+		return nil, existing, nil
+	}
+
+	created, err = c.collection.Indexes().CreateMany(ctx, missing)
+	if err != nil {
+		return nil, existing, operationError(ctx, err, ErrorIndexFailed)
+	}
+
+	return created, existing, nil
+}
+
+// indexOptionKeys are the index options compared by EnsureIndexes
+var indexOptionKeys = []string{"unique", "sparse", "expireAfterSeconds", "partialFilterExpression"}
+
+// indexSpec encodes the model the way the server lists its indexes, with the key pattern under key
+func indexSpec(model mongo.IndexModel) (bson.Raw, error) {
+	spec := bson.D{{Key: "key", Value: model.Keys}}
+
+	if opts := model.Options; opts != nil {
+		if opts.Unique != nil && *opts.Unique {
+			spec = append(spec, bson.E{Key: "unique", Value: true})
+		}
+		if opts.Sparse != nil && *opts.Sparse {
+			spec = append(spec, bson.E{Key: "sparse", Value: true})
+		}
+		if opts.ExpireAfterSeconds != nil {
+			spec = append(spec, bson.E{Key: "expireAfterSeconds", Value: *opts.ExpireAfterSeconds})
+		}
+		if opts.PartialFilterExpression != nil {
+			spec = append(spec, bson.E{Key: "partialFilterExpression", Value: opts.PartialFilterExpression})
+		}
+	}
+
+	return bson.Marshal(spec)
+}
+
+// matchingIndex returns the name of the index in current with the key pattern and options of spec
+func matchingIndex(current []bson.Raw, spec bson.Raw) (string, bool) {
+	for _, index := range current {
+		if !sameBSON(index.Lookup("key"), spec.Lookup("key")) {
+			continue
+		}
+
+		matches := true
+		for _, key := range indexOptionKeys {
+			want, got := spec.Lookup(key), index.Lookup(key)
+			// The server omits unique and sparse when false
+			if want.Type == 0 && (got.Type == 0 || got.Type == bsontype.Boolean && !got.Boolean()) {
+				continue
+			}
+			if !sameBSON(want, got) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			name, _ := index.Lookup("name").StringValueOK()
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// sameBSON compares two values ignoring the width of numbers, since an index declared with 1 can be listed back as
+// an int32, an int64 or a double. Documents and arrays are compared element by element in order.
+func sameBSON(a, b bson.RawValue) bool {
+	if x, ok := numberValue(a); ok {
+		y, ok := numberValue(b)
+		return ok && x == y
+	}
+
+	if a.Type != b.Type {
+		return false
+	}
+
+	if a.Type != bsontype.EmbeddedDocument && a.Type != bsontype.Array {
+		return bytes.Equal(a.Value, b.Value)
+	}
+
+	ae, err := bson.Raw(a.Value).Elements()
+	if err != nil {
+		return false
+	}
+	be, err := bson.Raw(b.Value).Elements()
+	if err != nil || len(ae) != len(be) {
+		return false
+	}
+
+	for i := range ae {
+		if ae[i].Key() != be[i].Key() || !sameBSON(ae[i].Value(), be[i].Value()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// numberValue returns v as a float64 when it is an int32, an int64 or a double
+func numberValue(v bson.RawValue) (float64, bool) {
+	switch v.Type {
+	case bsontype.Int32:
+		return float64(v.Int32()), true
+	case bsontype.Int64:
+		return float64(v.Int64()), true
+	case bsontype.Double:
+		return v.Double(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,46 @@
+package mongocrud
+
+import (
+	// Standard
+	"sync"
+)
+
+// idLocks hands out a mutex per document id, forgetting each one once nobody holds or waits on it
+type idLocks struct {
+	mu    sync.Mutex
+	locks map[interface{}]*idLock
+}
+
+type idLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newIDLocks() *idLocks {
+	return &idLocks{locks: map[interface{}]*idLock{}}
+}
+
+// lock blocks until the id is free and returns the function that releases it
+func (l *idLocks) lock(id interface{}) func() {
+	l.mu.Lock()
+	entry, ok := l.locks[id]
+	if !ok {
+		entry = &idLock{}
+		l.locks[id] = entry
+	}
+	entry.refs++
+	l.mu.Unlock()
+
+	entry.Lock()
+
+	return func() {
+		entry.Unlock()
+
+		l.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(l.locks, id)
+		}
+		l.mu.Unlock()
+	}
+}
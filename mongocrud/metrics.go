@@ -0,0 +1,83 @@
+package mongocrud
+
+import (
+	// Standard
+	"errors"
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
+
+// expvarName is the top level expvar the counters are published under
+const expvarName = "mongocrud"
+
+var (
+	metricsOnce    sync.Once
+	metricsEnabled int32
+
+	// operationCounts counts calls by collection and operation, such as "users.GetItem"
+	operationCounts = new(expvar.Map)
+	// errorCounts counts failed calls by the sentinel of the error returned to the caller, such as "item not found"
+	errorCounts = new(expvar.Map)
+)
+
+// publishExpvar registers the counters with expvar and starts counting. Registering only happens once, since expvar
+// panics on a duplicate name.
+func publishExpvar() {
+	metricsOnce.Do(func() {
+		m := expvar.NewMap(expvarName)
+		m.Set("operations", operationCounts)
+		m.Set("errors", errorCounts)
+
+		atomic.StoreInt32(&metricsEnabled, 1)
+	})
+}
+
+// countOperation counts a call to operation on the collection when the counters are published
+func countOperation(collection, operation string) {
+	if atomic.LoadInt32(&metricsEnabled) == 0 {
+		return
+	}
+
+	operationCounts.Add(collection+"."+operation, 1)
+}
+
+// otherError is the key errors that wrap none of countedErrors are counted under
+const otherError = "other"
+
+// countedErrors are the sentinels errors are counted by, so that the wrapped details of an error do not each add a
+// counter. The more specific sentinels come first, since a missing document also matches the operation's failure.
+var countedErrors = []error{
+	ErrorNotFound,
+	ErrorContextCancelled,
+	ErrorTimeout,
+	ErrorReadOnly,
+	ErrorDocumentTooLarge,
+	ErrorTooManyDocuments,
+	ErrorInsertFailed,
+	ErrorGetFailed,
+	ErrorDeleteFailed,
+	ErrorUpdateFailed,
+	ErrorExplainFailed,
+	ErrorAggregateFailed,
+	ErrorIndexFailed,
+	ErrorDecodeFailed,
+}
+
+// countError counts err by the sentinel it wraps when the counters are published. It is called once per failure, by
+// observe at the public method that returns it, or by the long running Tail and Stream.
+func countError(err error) {
+	if err == nil || atomic.LoadInt32(&metricsEnabled) == 0 {
+		return
+	}
+
+	key := otherError
+	for _, sentinel := range countedErrors {
+		if errors.Is(err, sentinel) {
+			key = sentinel.Error()
+			break
+		}
+	}
+
+	errorCounts.Add(key, 1)
+}
@@ -0,0 +1,109 @@
+package mongocrud
+
+import (
+	// Standard
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrationsCollection records the migrations that have completed, keyed by migration id
+const migrationsCollection = "_migrations"
+
+// Migrate applies fn to every document in coll in batches of batchSize and writes the changed documents back with a
+// bulk write. fn returns nil to leave a document as it is. Once every batch is written the migration id is recorded in
+// the _migrations collection and later calls with the same id do nothing. A migration that fails part way runs again
+// from the start, so fn should be safe to apply to a document it has already transformed.
+func Migrate(ctx context.Context, coll *DatabaseCollection, migrationID string, fn func(doc bson.M) (bson.M, error), batchSize int) error {
+	if batchSize <= 0 {
+		return ErrorBatchSizeInvalid
+	}
+	if coll.ReadOnly {
+		return ErrorReadOnly
+	}
+
+	migrations := coll.collection.Database().Collection(migrationsCollection)
+
+	err := migrations.FindOne(ctx, bson.D{{Key: "_id", Value: migrationID}}).Err()
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return operationError(ctx, err, ErrorGetFailed)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetBatchSize(int32(batchSize))
+
+	cursor, err := coll.collection.Find(ctx, bson.D{}, opts)
+	if err != nil {
+		return operationError(ctx, err, ErrorGetFailed)
+	}
+	defer cursor.Close(context.Background())
+
+	batch := make([]mongo.WriteModel, 0, batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if _, err := coll.collection.BulkWrite(ctx, batch); err != nil {
+			return operationError(ctx, err, ErrorUpdateFailed)
+		}
+
+		batch = batch[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return operationError(ctx, err, ErrorDecodeFailed)
+		}
+
+		id := doc["_id"]
+
+		out, err := fn(doc)
+		if err != nil {
+			return fmt.Errorf("%w: %s: document %v: %v", ErrorMigrationFailed, migrationID, id, err)
+		}
+		if out == nil {
+			continue
+		}
+
+		out["_id"] = id
+		batch = append(batch, mongo.NewReplaceOneModel().SetFilter(bson.D{{Key: "_id", Value: id}}).SetReplacement(out))
+
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := cursor.Err(); err != nil {
+		return operationError(ctx, err, ErrorGetFailed)
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	_, err = migrations.InsertOne(ctx, bson.D{
+		{Key: "_id", Value: migrationID},
+		{Key: "collection", Value: coll.name},
+		{Key: "completedAt", Value: time.Now().UTC()},
+	})
+	// A concurrent runner finishing first has already recorded the same migration
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return operationError(ctx, err, ErrorInsertFailed)
+	}
+
+	return nil
+}
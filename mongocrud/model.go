@@ -0,0 +1,194 @@
+package mongocrud
+
+import (
+	// Standard
+	"fmt"
+	"reflect"
+	"strings"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// bsonField describes how the driver encodes a struct field
+type bsonField struct {
+	Key    string
+	Inline bool
+}
+
+// parseBSONTag returns the encoding of a struct field following the driver's default struct codec, and false when the
+// field is not encoded at all
+func parseBSONTag(f reflect.StructField) (bsonField, bool) {
+	if f.PkgPath != "" {
+		return bsonField{}, false
+	}
+
+	tag := f.Tag.Get("bson")
+	if tag == "-" {
+		return bsonField{}, false
+	}
+
+	parts := strings.Split(tag, ",")
+
+	resp := bsonField{Key: parts[0]}
+	if resp.Key == "" {
+		resp.Key = strings.ToLower(f.Name)
+	}
+
+	for _, opt := range parts[1:] {
+		if opt == "inline" {
+			resp.Inline = true
+		}
+	}
+
+	return resp, true
+}
+
+// BSONKey returns the bson key the driver encodes the struct's Go field under, including fields of inline structs,
+// so hand built filters follow field renames. It returns ErrorFieldNotFound when the struct has no such field or the
+// field is not encoded, such as one tagged bson:"-".
+func BSONKey(structPtr interface{}, goFieldName string) (string, error) {
+	rv := reflect.Indirect(reflect.ValueOf(structPtr))
+	if rv.Kind() != reflect.Struct {
+		return "", ErrorValueNotStruct
+	}
+
+	if key, ok := bsonKey(rv.Type(), goFieldName); ok {
+		return key, nil
+	}
+
+	return "", ErrorFieldNotFound
+}
+
+func bsonKey(t reflect.Type, name string) (string, bool) {
+	for n := 0; n < t.NumField(); n++ {
+		f := t.Field(n)
+
+		field, ok := parseBSONTag(f)
+		if !ok {
+			continue
+		}
+
+		if f.Name == name && !field.Inline {
+			return field.Key, true
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if field.Inline && ft.Kind() == reflect.Struct {
+			if key, ok := bsonKey(ft, name); ok {
+				return key, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// ValidateModel checks the bson mapping of a struct, or a pointer to one, for mistakes the driver accepts silently:
+// two fields encoded under the same key, including through inline structs, an ID field not tagged bson:"_id" or no
+// field mapped to _id at all, and unexported fields with a bson tag, which are never encoded. Every problem found is
+// listed in the returned error, which wraps ErrorModelInvalid.
+func ValidateModel(i interface{}) error {
+	t := reflect.TypeOf(i)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return ErrorValueNotStruct
+	}
+
+	var problems []string
+	keys := map[string]string{}
+	validateFields(t, keys, &problems)
+
+	if _, ok := keys["_id"]; !ok {
+		problems = append(problems, "no field is mapped to _id")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s: %s", ErrorModelInvalid, t.Name(), strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// validateFields records the key of each encoded field of t in keys, flattening inline structs the way the driver
+// does, and appends every problem found
+func validateFields(t reflect.Type, keys map[string]string, problems *[]string) {
+	for n := 0; n < t.NumField(); n++ {
+		f := t.Field(n)
+
+		field, ok := parseBSONTag(f)
+		if !ok {
+			if f.PkgPath != "" && !f.Anonymous && f.Tag.Get("bson") != "" {
+				*problems = append(*problems, fmt.Sprintf("unexported field %s has a bson tag but is never encoded", f.Name))
+			}
+			continue
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if field.Inline && ft.Kind() == reflect.Struct {
+			validateFields(ft, keys, problems)
+			continue
+		}
+
+		if f.Name == "ID" && field.Key != "_id" {
+			*problems = append(*problems, fmt.Sprintf("field ID is encoded as %q instead of \"_id\"", field.Key))
+		}
+
+		if other, ok := keys[field.Key]; ok {
+			*problems = append(*problems, fmt.Sprintf("fields %s and %s are both encoded as %q", other, f.Name, field.Key))
+			continue
+		}
+		keys[field.Key] = f.Name
+	}
+}
+
+// BuildSetFromStruct returns the $set document for the struct's non-zero fields, keyed by their bson keys. Pointer
+// fields are included whenever they are not nil, so a pointer to a zero value sets the field to zero while a nil
+// pointer leaves it unchanged. The _id field is never included.
+func BuildSetFromStruct(i interface{}) bson.M {
+	resp := bson.M{}
+
+	rv := reflect.Indirect(reflect.ValueOf(i))
+	if rv.Kind() != reflect.Struct {
+		return resp
+	}
+
+	buildSet(rv, resp)
+	return resp
+}
+
+func buildSet(rv reflect.Value, set bson.M) {
+	for n := 0; n < rv.NumField(); n++ {
+		field, ok := parseBSONTag(rv.Type().Field(n))
+		if !ok || field.Key == "_id" {
+			continue
+		}
+
+		v := rv.Field(n)
+
+		if field.Inline && reflect.Indirect(v).Kind() == reflect.Struct {
+			if v.Kind() == reflect.Ptr && v.IsNil() {
+				continue
+			}
+			buildSet(reflect.Indirect(v), set)
+			continue
+		}
+
+		switch {
+		case v.Kind() == reflect.Ptr:
+			if !v.IsNil() {
+				set[field.Key] = v.Elem().Interface()
+			}
+		case !v.IsZero():
+			set[field.Key] = v.Interface()
+		}
+	}
+}
@@ -3,31 +3,178 @@ package mongocrud
 import (
 	// Standard
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
 	"time"
 
 	// External
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 	"go.uber.org/zap"
 )
 
+// unauthorized is the server code for a command the user lacks the privileges to run
+const unauthorized = 13
+
+// defaultDNSTimeout bounds the seedlist lookups when DNSTimeout is not set
+const defaultDNSTimeout = 5 * time.Second
+
+// primaryPollInterval is how often WaitForPrimary checks for a primary
+const primaryPollInterval = 500 * time.Millisecond
+
+var (
+	ErrorCollectionsMissing = errors.New("required collections are missing")
+	ErrorDNSResolution      = errors.New("failed to resolve the connection url")
+	ErrorPermissionDenied   = errors.New("user is not authorized for the operation")
+	ErrorNoPrimary          = errors.New("no primary was elected in time")
+	ErrorOplogMissing       = errors.New("server has no oplog, it is not a replica set member")
+)
+
 type DatabaseConfiguration struct {
 	DatabaseUser          string
 	DatabasePassword      string
 	DatabaseConnectionUrl string
 	DatabaseName          string
+
+	// DisableSRV connects to DatabaseConnectionUrl as a plain host list instead of resolving it as a mongodb+srv
+	// seedlist
+	DisableSRV bool
+	// DNSTimeout bounds the SRV and TXT lookups of the seedlist, so a blocked DNS server fails fast with
+	// ErrorDNSResolution instead of surfacing as a connection timeout
+	DNSTimeout time.Duration
+
+	// URIParams are added to the connection string query, overriding the package defaults, for driver options that
+	// are not modelled here
+	URIParams map[string]string
+
+	// Compressors enables wire protocol compression with the first of these the server supports: snappy, zlib or
+	// zstd
+	Compressors []string
+
+	// ReadPreferenceTags are the tags of the secondaries that reads through a collection's WithTaggedReads view are
+	// routed to, such as {"nodeType": "ANALYTICS"}. Other reads keep the client's read preference.
+	ReadPreferenceTags map[string]string
+
+	// AutoEncryption enables Client-Side Field Level Encryption, transparently encrypting and decrypting the
+	// configured fields. It requires the binary to be built with the cse tag against libmongocrypt.
+	AutoEncryption *options.AutoEncryptionOptions
+
+	// LogConnectionURI logs the connection string at debug level when the client is created, with the password
+	// masked, to check how the host, database and parameters were assembled
+	LogConnectionURI bool
+
+	// PublishExpvar publishes per-operation and per-error counters under the mongocrud expvar, served on
+	// /debug/vars by the expvar handler. The counters are shared by every client in the process.
+	PublishExpvar bool
+
+	// Registry overrides the BSON codecs used by the client, for types such as decimals or custom enums
+	Registry *bsoncodec.Registry
+}
+
+// Timeouts bound the operations of a client's collections whose context has no deadline, zero leaves that kind of
+// operation unbounded. Cursors are only bounded until the first batch is returned.
+type Timeouts struct {
+	// Read bounds finds, counts, aggregations, explains, collection stats and opening change streams
+	Read time.Duration
+	// Write bounds inserts, updates, upserts and index creation
+	Write time.Duration
+	// Delete bounds deletes, including soft deletes
+	Delete time.Duration
 }
 
 type DatabaseClient struct {
 	Instance *mongo.Client
 
-	Database    *mongo.Database
+	// Timeouts are applied to every registered collection, and should be set before they are used
+	Timeouts Timeouts
+
+	Database *mongo.Database
+	// Collections are the registered collections. Reading or changing the slice directly is not synchronized, so
+	// code running alongside other goroutines should go through AddCollections and GetCollection.
 	Collections []*DatabaseCollection
 
-	logger *zap.Logger
+	// mu guards Collections
+	mu       sync.RWMutex
+	logger   *zap.Logger
+	registry *bsoncodec.Registry
+	readTags map[string]string
+}
+
+// connectionURI builds the connection string for the configuration
+func (c *DatabaseConfiguration) connectionURI() string {
+	return c.buildURI(escapeCredential(c.DatabasePassword))
+}
+
+// redactedURI is the connection string with the password masked, safe to log
+func (c *DatabaseConfiguration) redactedURI() string {
+	return c.buildURI("****")
+}
+
+// buildURI assembles the connection string around an already escaped password
+func (c *DatabaseConfiguration) buildURI(password string) string {
+	params := url.Values{}
+	params.Set("retryWrites", "true")
+	params.Set("w", "majority")
+
+	for key, value := range c.URIParams {
+		params.Set(key, value)
+	}
+
+	scheme := "mongodb+srv"
+	if c.DisableSRV {
+		scheme = "mongodb"
+	}
+
+	return fmt.Sprintf("%s://%s:%s@%s/%s?%s",
+		scheme,
+		escapeCredential(c.DatabaseUser),
+		password,
+		c.DatabaseConnectionUrl,
+		c.DatabaseName,
+		params.Encode(),
+	)
+}
+
+// escapeCredential percent-encodes a user name or password for the connection string, so characters such as @, / and
+// : do not end the userinfo early. The driver path-unescapes credentials, which would read a query-escaped space "+"
+// as a literal plus, so spaces are encoded as %20.
+func escapeCredential(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// resolveSeedlist looks up the SRV and TXT records the driver needs for a mongodb+srv connection within the DNS
+// timeout, reporting a failure as ErrorDNSResolution
+func (c *DatabaseConfiguration) resolveSeedlist() error {
+	timeout := c.DNSTimeout
+	if timeout <= 0 {
+		timeout = defaultDNSTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if _, _, err := net.DefaultResolver.LookupSRV(ctx, "mongodb", "tcp", c.DatabaseConnectionUrl); err != nil {
+		return fmt.Errorf("%w: srv lookup: %v", ErrorDNSResolution, err)
+	}
+
+	// A missing TXT record is allowed, but a lookup that never answers would stall the driver
+	_, err := net.DefaultResolver.LookupTXT(ctx, c.DatabaseConnectionUrl)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsTimeout {
+		return fmt.Errorf("%w: txt lookup: %v", ErrorDNSResolution, err)
+	}
+
+	return nil
 }
 
 // NewStorage creates a Mongo client for communicating with Mongo DB's
@@ -35,6 +182,11 @@ func NewStorage(c *DatabaseConfiguration, l *zap.Logger) (*DatabaseClient, error
 	resp := &DatabaseClient{}
 	// Set package variables
 	resp.logger = l.With(zap.String("package", "mongocrud"))
+	resp.registry = c.Registry
+	resp.readTags = c.ReadPreferenceTags
+	if c.PublishExpvar {
+		publishExpvar()
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -44,14 +196,43 @@ func NewStorage(c *DatabaseConfiguration, l *zap.Logger) (*DatabaseClient, error
 	)
 
 	// MongoDB Init
-	var uri string = fmt.Sprintf("mongodb+srv://%s:%s@%s/%s?retryWrites=true&w=majority",
-		c.DatabaseUser,
-		c.DatabasePassword,
-		c.DatabaseConnectionUrl,
-		c.DatabaseName,
-	)
-	resp.Instance, err = mongo.NewClient(options.Client().ApplyURI(uri))
+	if !c.DisableSRV {
+		err = c.resolveSeedlist()
+		if err != nil {
+			resp.logger.Error("dns resolution failed",
+				zap.String("func", "GetInstance"),
+				zap.String("host", c.DatabaseConnectionUrl),
+				zap.Error(err),
+			)
+			return resp, err
+		}
+	}
+
+	if c.LogConnectionURI {
+		resp.logger.Debug("connection uri",
+			zap.String("func", "GetInstance"),
+			zap.String("uri", c.redactedURI()),
+		)
+	}
+
+	opts := options.Client().ApplyURI(c.connectionURI())
+	if c.Registry != nil {
+		opts.SetRegistry(c.Registry)
+	}
+	if len(c.Compressors) > 0 {
+		opts.SetCompressors(c.Compressors)
+	}
+	if c.AutoEncryption != nil {
+		opts.SetAutoEncryptionOptions(c.AutoEncryption)
+	}
+
+	resp.Instance, err = mongo.NewClient(opts)
 	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			err = fmt.Errorf("%w: %v", ErrorDNSResolution, err)
+		}
+
 		resp.logger.Error("new client failed",
 			zap.String("func", "GetInstance"),
 			zap.Error(err),
@@ -79,48 +260,173 @@ func NewStorage(c *DatabaseConfiguration, l *zap.Logger) (*DatabaseClient, error
 	return resp, nil
 }
 
-// Ping sends a ping to the Mongo client to determine if the connection is still alive
-func (s DatabaseClient) Ping() {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// ForDatabase returns a client for another database on the same cluster that shares this client's connection pool,
+// for example one database per tenant. No collections are registered on it.
+func (c *DatabaseClient) ForDatabase(name string) *DatabaseClient {
+	return &DatabaseClient{
+		Instance: c.Instance,
+		Database: c.Instance.Database(name),
+		Timeouts: c.Timeouts,
+		logger:   c.logger.With(zap.String("database", name)),
+		registry: c.registry,
+		readTags: c.readTags,
+	}
+}
 
+// Ping sends a ping to the Mongo client to determine if the connection is still alive, giving up at the context's
+// deadline
+func (s *DatabaseClient) Ping(ctx context.Context) error {
 	err := s.Instance.Ping(ctx, readpref.Primary())
 	if err != nil {
 		s.logger.Error("ping failed",
 			zap.String("func", "Ping"),
 			zap.Error(err),
 		)
-	} else {
-		s.logger.Info("client ping success")
+		return err
+	}
+
+	s.logger.Info("client ping success")
+	return nil
+}
+
+// WarmUp opens up to connections pooled sockets ahead of traffic by pinging the primary concurrently, returning the
+// first ping failure
+func (c *DatabaseClient) WarmUp(ctx context.Context, connections int) error {
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	for i := 0; i < connections; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := c.Instance.Ping(ctx, readpref.Primary()); err != nil {
+				once.Do(func() { firstErr = err })
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		c.logger.Error("warm up failed",
+			zap.String("func", "WarmUp"),
+			zap.Error(firstErr),
+		)
+		return firstErr
 	}
+
+	return nil
 }
 
 // AddCollections appends to the current database collections (allows for mock collections to be added)
 func (c *DatabaseClient) AddCollections(ctx context.Context, cols []*DatabaseCollection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for i := range cols {
+		if cols[i].logger == nil {
+			cols[i].logger = c.logger
+		}
+		if cols[i].timeouts == nil {
+			cols[i].timeouts = &c.Timeouts
+		}
+		if cols[i].readTags == nil {
+			cols[i].readTags = c.readTags
+		}
 		c.Collections = append(c.Collections, cols[i])
 	}
 }
 
+// CollectionFilter selects collections by name using path.Match patterns, such as "orders_*". A name is selected
+// when it matches one of the Include patterns, or Include is empty, and none of the Exclude patterns.
+type CollectionFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// match reports whether the filter selects the collection name
+func (f CollectionFilter) match(name string) bool {
+	for _, pattern := range f.Exclude {
+		if ok, _ := path.Match(pattern, name); ok {
+			return false
+		}
+	}
+
+	if len(f.Include) == 0 {
+		return true
+	}
+
+	for _, pattern := range f.Include {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
 // MongoCollectionsToDatabaseCollections converts the Mongo DB collections present in the database to the local
-// database collection for use in program
-func (c *DatabaseClient) MongoCollectionsToDatabaseCollections(ctx context.Context) (resp []*DatabaseCollection) {
+// database collection for use in program. Only the collections selected by every filter are converted, and the
+// server's system.* collections never are.
+func (c *DatabaseClient) MongoCollectionsToDatabaseCollections(ctx context.Context, filters ...CollectionFilter) (resp []*DatabaseCollection) {
 	collectionStrings := c.ListCollections(ctx)
 
 	for _, collection := range collectionStrings {
-		temp := c.Database.Collection(collection)
+		if strings.HasPrefix(collection, "system.") || !matchesAll(filters, collection) {
+			continue
+		}
 
-		resp = append(resp, &DatabaseCollection{
-			name:       collection,
-			collection: temp,
-		})
+		resp = append(resp, c.newCollection(collection))
 	}
 
 	return resp
 }
 
+func matchesAll(filters []CollectionFilter, name string) bool {
+	for _, f := range filters {
+		if !f.match(name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// collectionOrAdd returns the registered collection with the name, registering a new one when there is none. The
+// lookup and the registration happen under one lock, so concurrent callers never register the name twice.
+func (c *DatabaseClient) collectionOrAdd(name string) *DatabaseCollection {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, collection := range c.Collections {
+		if collection.name == name {
+			return collection
+		}
+	}
+
+	collection := c.newCollection(name)
+	c.Collections = append(c.Collections, collection)
+
+	return collection
+}
+
+// newCollection wraps the named Mongo DB collection of the configured database
+func (c *DatabaseClient) newCollection(name string) *DatabaseCollection {
+	return &DatabaseCollection{
+		name:       name,
+		collection: c.Database.Collection(name),
+		logger:     c.logger,
+		registry:   c.registry,
+		timeouts:   &c.Timeouts,
+		readTags:   c.readTags,
+	}
+}
+
 // ListCollections returns a slice of collections of the configured database
-func (c DatabaseClient) ListCollections(ctx context.Context) []string {
+func (c *DatabaseClient) ListCollections(ctx context.Context) []string {
 	collections, err := c.Database.ListCollectionNames(ctx, bson.M{})
 	if err != nil {
 		c.logger.Warn("get collections failed",
@@ -133,6 +439,9 @@ func (c DatabaseClient) ListCollections(ctx context.Context) []string {
 }
 
 func (c *DatabaseClient) GetCollection(collectionName string) *DatabaseCollection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	for i := range c.Collections {
 		if c.Collections[i].name == collectionName {
 			return c.Collections[i]
@@ -141,3 +450,315 @@ func (c *DatabaseClient) GetCollection(collectionName string) *DatabaseCollectio
 
 	return nil
 }
+
+// RenameCollection renames a collection in the configured database and registers the collection under its new name,
+// dropping any existing target collection first when dropTarget is set. A *DatabaseCollection obtained before the
+// rename keeps the old name; GetCollection returns the renamed one.
+func (c *DatabaseClient) RenameCollection(ctx context.Context, oldName, newName string, dropTarget bool) error {
+	dbName := c.Database.Name()
+
+	cmd := bson.D{
+		{Key: "renameCollection", Value: dbName + "." + oldName},
+		{Key: "to", Value: dbName + "." + newName},
+		{Key: "dropTarget", Value: dropTarget},
+	}
+
+	err := c.Instance.Database("admin").RunCommand(ctx, cmd).Err()
+	if err != nil {
+		c.logger.Error("rename collection failed",
+			zap.String("func", "RenameCollection"),
+			zap.String("collection", oldName),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// A dropped target no longer exists, so stop tracking it
+	collections := c.Collections[:0]
+	for _, collection := range c.Collections {
+		if collection.name != newName {
+			collections = append(collections, collection)
+		}
+	}
+	c.Collections = collections
+
+	// Collections are used without the lock, so the renamed one is registered as a copy instead of being changed in
+	// place under a caller using it
+	for n, collection := range c.Collections {
+		if collection.name == oldName {
+			renamed := *collection
+			renamed.name = newName
+			renamed.collection = c.Database.Collection(newName)
+			c.Collections[n] = &renamed
+		}
+	}
+
+	return nil
+}
+
+// IsReplicaSet reports whether the client is connected to a replica set, which transactions and change streams
+// require. Servers too old to know the hello command are asked with isMaster instead.
+func (c *DatabaseClient) IsReplicaSet(ctx context.Context) (bool, error) {
+	var resp struct {
+		SetName string `bson:"setName"`
+	}
+
+	admin := c.Instance.Database("admin")
+
+	err := admin.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&resp)
+	if err != nil {
+		err = admin.RunCommand(ctx, bson.D{{Key: "isMaster", Value: 1}}).Decode(&resp)
+	}
+	if err != nil {
+		c.logger.Error("topology check failed",
+			zap.String("func", "IsReplicaSet"),
+			zap.Error(err),
+		)
+		return false, err
+	}
+
+	return resp.SetName != "", nil
+}
+
+// CollectionExists reports whether the configured database has a collection with the name
+func (c *DatabaseClient) CollectionExists(ctx context.Context, name string) (bool, error) {
+	names, err := c.Database.ListCollectionNames(ctx, bson.D{{Key: "name", Value: name}})
+	if err != nil {
+		c.logger.Warn("get collections failed",
+			zap.String("func", "CollectionExists"),
+			zap.Error(err),
+		)
+		return false, err
+	}
+
+	return len(names) > 0, nil
+}
+
+// RequireCollections checks that every named collection exists in the configured database, returning an error that
+// lists the missing ones. Calling it at startup catches a client pointed at the wrong database.
+func (c *DatabaseClient) RequireCollections(ctx context.Context, names ...string) error {
+	existing, err := c.Database.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		c.logger.Warn("get collections failed",
+			zap.String("func", "RequireCollections"),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	found := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		found[name] = true
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrorCollectionsMissing, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// ListDatabases returns the names of the databases on the cluster. A user without the listDatabases privilege gets an
+// error wrapping ErrorPermissionDenied rather than an empty list.
+func (c *DatabaseClient) ListDatabases(ctx context.Context) ([]string, error) {
+	names, err := c.Instance.ListDatabaseNames(ctx, bson.D{})
+	if err != nil {
+		c.logger.Warn("get databases failed",
+			zap.String("func", "ListDatabases"),
+			zap.Error(err),
+		)
+
+		var se mongo.ServerError
+		if errors.As(err, &se) && se.HasErrorCode(unauthorized) {
+			return nil, fmt.Errorf("%w: %v", ErrorPermissionDenied, err)
+		}
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// WithSession runs fn inside a causally consistent session so reads observe the session's earlier writes, even
+// against secondaries. Unlike a transaction, each operation in fn commits on its own.
+func (c *DatabaseClient) WithSession(ctx context.Context, fn func(sessCtx mongo.SessionContext) error) error {
+	opts := options.Session().SetCausalConsistency(true)
+
+	err := c.Instance.UseSessionWithOptions(ctx, opts, fn)
+	if err != nil {
+		c.logger.Error("session failed",
+			zap.String("func", "WithSession"),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	return nil
+}
+
+// OplogTail delivers the replica set oplog entries written after the timestamp to fn, blocking until the context is
+// cancelled, fn returns an error or the query fails. When the cursor dies or the server discards it, it is reopened
+// after the last delivered entry's ts, so entries are not delivered twice. Reading local.oplog.rs needs a user with
+// read access to the local database, or the error wraps ErrorPermissionDenied, and a server that is not a replica set
+// member has no oplog and returns ErrorOplogMissing.
+func (c *DatabaseClient) OplogTail(ctx context.Context, after primitive.Timestamp, fn func(bson.Raw) error) error {
+	local := c.Instance.Database("local")
+
+	// A missing oplog would only ever return dead cursors, so check for it once up front
+	names, err := local.ListCollectionNames(ctx, bson.D{{Key: "name", Value: "oplog.rs"}})
+	if err != nil {
+		return c.oplogError(err)
+	}
+	if len(names) == 0 {
+		return ErrorOplogMissing
+	}
+
+	oplog := local.Collection("oplog.rs")
+	opts := options.Find().SetCursorType(options.TailableAwait)
+
+	for {
+		cursor, err := oplog.Find(ctx, bson.D{{Key: "ts", Value: bson.D{{Key: "$gt", Value: after}}}}, opts)
+		if err != nil {
+			return c.oplogError(err)
+		}
+
+		for cursor.Next(ctx) {
+			if err := fn(cursor.Current); err != nil {
+				cursor.Close(context.Background())
+				return err
+			}
+
+			if t, i, ok := cursor.Current.Lookup("ts").TimestampOK(); ok {
+				after = primitive.Timestamp{T: t, I: i}
+			}
+		}
+
+		err = cursor.Err()
+		cursor.Close(context.Background())
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && !isCursorLostError(err) {
+			return c.oplogError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(tailRetryInterval):
+		}
+	}
+}
+
+// oplogError logs a failure that ends OplogTail, wrapping a missing read privilege on local in ErrorPermissionDenied
+func (c *DatabaseClient) oplogError(err error) error {
+	c.logger.Error("oplog tail failed",
+		zap.String("func", "OplogTail"),
+		zap.Error(err),
+	)
+
+	var se mongo.ServerError
+	if errors.As(err, &se) && se.HasErrorCode(unauthorized) {
+		return fmt.Errorf("%w: %v", ErrorPermissionDenied, err)
+	}
+
+	return err
+}
+
+// DropDatabase drops the configured database and forgets its collections, for tearing down test environments. It
+// does nothing unless confirmName is the database's name, so a production database cannot be dropped by accident.
+func (c *DatabaseClient) DropDatabase(ctx context.Context, confirmName string) error {
+	if confirmName == "" || confirmName != c.Database.Name() {
+		return ErrorConfirmationRequired
+	}
+
+	err := c.Database.Drop(ctx)
+	if err != nil {
+		c.logger.Error("drop database failed",
+			zap.String("func", "DropDatabase"),
+			zap.String("database", confirmName),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	c.mu.Lock()
+	c.Collections = nil
+	c.mu.Unlock()
+
+	return nil
+}
+
+// WaitForPrimary blocks until the replica set has a primary that accepts writes, for holding traffic through a
+// failover instead of failing it. It returns ErrorNoPrimary wrapping the last failure once the timeout elapses, and
+// ErrorContextCancelled when the context is cancelled first.
+func (c *DatabaseClient) WaitForPrimary(ctx context.Context, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		attempt, cancel := context.WithTimeout(ctx, primaryPollInterval)
+		err := c.Instance.Ping(attempt, readpref.Primary())
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return operationError(ctx, ctx.Err(), ctx.Err())
+		}
+
+		if !time.Now().Before(deadline) {
+			c.logger.Warn("no primary elected",
+				zap.String("func", "WaitForPrimary"),
+				zap.Duration("timeout", timeout),
+				zap.Error(err),
+			)
+			return fmt.Errorf("%w: %v", ErrorNoPrimary, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return operationError(ctx, ctx.Err(), ctx.Err())
+		case <-time.After(primaryPollInterval):
+		}
+	}
+}
+
+// EffectiveWriteConcern returns the write concern the configured database's writes use, as parsed from the connection
+// string, such as w=majority. A nil result means none was set and the server's default applies.
+func (c *DatabaseClient) EffectiveWriteConcern() *writeconcern.WriteConcern {
+	return c.Database.WriteConcern()
+}
+
+// CreateTimeSeriesCollection creates a time-series collection, available from MongoDB 5.0, and registers it. The
+// metaField and granularity ("seconds", "minutes" or "hours") are optional and left to the server when empty.
+func (c *DatabaseClient) CreateTimeSeriesCollection(ctx context.Context, name string, timeField, metaField string, granularity string) (*DatabaseCollection, error) {
+	ts := options.TimeSeries().SetTimeField(timeField)
+	if metaField != "" {
+		ts.SetMetaField(metaField)
+	}
+	if granularity != "" {
+		ts.SetGranularity(granularity)
+	}
+
+	err := c.Database.CreateCollection(ctx, name, options.CreateCollection().SetTimeSeriesOptions(ts))
+	if err != nil {
+		c.logger.Error("create time-series collection failed",
+			zap.String("func", "CreateTimeSeriesCollection"),
+			zap.String("collection", name),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	return c.collectionOrAdd(name), nil
+}
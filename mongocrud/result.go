@@ -0,0 +1,99 @@
+package mongocrud
+
+import (
+	// Standard
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SingleResultToJSON encodes the document in the result as plain JSON, with ObjectIDs as hex strings, dates as
+// RFC 3339 strings and decimals as strings
+func SingleResultToJSON(res *mongo.SingleResult) ([]byte, error) {
+	if res == nil {
+		return nil, ErrorGetFailed
+	}
+
+	var doc bson.M
+	if err := res.Decode(&doc); err != nil {
+		return nil, ErrorDecodeFailed
+	}
+
+	return json.Marshal(plainJSON(doc))
+}
+
+// DecodeWithID decodes the document in the result into dest and also returns its _id. It returns ErrorIdMissing when
+// the document has no _id and ErrorIdInvalid when the _id is not an ObjectID.
+func DecodeWithID(res *mongo.SingleResult, dest interface{}) (primitive.ObjectID, error) {
+	if res == nil {
+		return primitive.NilObjectID, ErrorGetFailed
+	}
+
+	raw, err := res.DecodeBytes()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return primitive.NilObjectID, ErrorNotFound
+	}
+	if err != nil {
+		return primitive.NilObjectID, ErrorGetFailed
+	}
+
+	value, err := raw.LookupErr("_id")
+	if err != nil {
+		return primitive.NilObjectID, ErrorIdMissing
+	}
+
+	id, ok := value.ObjectIDOK()
+	if !ok {
+		return primitive.NilObjectID, fmt.Errorf("%w: _id is a %s", ErrorIdInvalid, value.Type)
+	}
+
+	if err := res.Decode(dest); err != nil {
+		return primitive.NilObjectID, ErrorDecodeFailed
+	}
+
+	return id, nil
+}
+
+// plainJSON converts decoded BSON values into values that encode as readable JSON
+func plainJSON(v interface{}) interface{} {
+	switch t := v.(type) {
+	case primitive.M:
+		return plainJSON(map[string]interface{}(t))
+	case map[string]interface{}:
+		resp := make(map[string]interface{}, len(t))
+		for key, value := range t {
+			resp[key] = plainJSON(value)
+		}
+		return resp
+	case primitive.D:
+		resp := make(map[string]interface{}, len(t))
+		for _, e := range t {
+			resp[e.Key] = plainJSON(e.Value)
+		}
+		return resp
+	case primitive.A:
+		return plainJSON([]interface{}(t))
+	case []interface{}:
+		resp := make([]interface{}, len(t))
+		for i, value := range t {
+			resp[i] = plainJSON(value)
+		}
+		return resp
+	case primitive.ObjectID:
+		return t.Hex()
+	case primitive.DateTime:
+		return t.Time().UTC().Format(time.RFC3339)
+	case time.Time:
+		return t.UTC().Format(time.RFC3339)
+	case primitive.Decimal128:
+		return t.String()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,137 @@
+package mongocrud
+
+import (
+	// Standard
+	"context"
+	"errors"
+	"time"
+
+	// External
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// defaultWriteRetryBackoff is the delay before the first retry when WriteRetryBackoff is not set
+const defaultWriteRetryBackoff = 100 * time.Millisecond
+
+// documentValidationFailure is the server code for a write rejected by the collection's validator
+const documentValidationFailure = 121
+
+// notPrimaryCodes are the server codes for an operation that reached a member which is no longer, or not yet, the
+// primary: NotWritablePrimary, NotPrimaryNoSecondaryOk, NotPrimaryOrSecondary, PrimarySteppedDown,
+// InterruptedDueToReplStateChange and ShutdownInProgress
+var notPrimaryCodes = []int{10107, 13435, 13436, 189, 11602, 91}
+
+// cursorLostCodes are the server codes for a cursor the server has discarded, after which a query can be reopened:
+// CursorNotFound, CappedPositionLost, QueryPlanKilled and CursorKilled
+var cursorLostCodes = []int{43, 136, 175, 237}
+
+// retryWrite runs write, retrying it up to WriteRetries times while it fails with a transient error. The delay starts
+// at WriteRetryBackoff and doubles after every attempt. A write inside a transaction is never retried on its own, since
+// the transaction is aborted by the failure and has to be retried as a whole.
+func (c *DatabaseCollection) retryWrite(ctx context.Context, write func() error) error {
+	backoff := c.WriteRetryBackoff
+	if backoff <= 0 {
+		backoff = defaultWriteRetryBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := write()
+		if err == nil || attempt >= c.WriteRetries || inTransaction(ctx) || !isTransientError(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff << attempt):
+		}
+	}
+}
+
+// retryDuplicateUpsert runs upsert again once when it fails with a duplicate key error. Two concurrent upserts that
+// both miss the filter race to insert, and the loser's second attempt matches the winner's document and updates it.
+func retryDuplicateUpsert(upsert func() error) error {
+	err := upsert()
+	if mongo.IsDuplicateKeyError(err) {
+		err = upsert()
+	}
+
+	return err
+}
+
+// inTransaction reports whether ctx carries a session with a running transaction
+func inTransaction(ctx context.Context) bool {
+	xs, ok := mongo.SessionFromContext(ctx).(mongo.XSession)
+	return ok && xs.ClientSession().TransactionRunning()
+}
+
+// isTransientError reports whether a write outside a transaction failed in a way that is safe to retry: the server
+// labelled it a RetryableWriteError, or there was no primary to send it to. Plain network errors are not retried, as
+// the write may have been applied before the connection dropped. Duplicate key and validation failures are never
+// retried since they would fail again.
+func isTransientError(err error) bool {
+	if mongo.IsDuplicateKeyError(err) {
+		return false
+	}
+
+	if isNoPrimaryError(err) {
+		return true
+	}
+
+	var se mongo.ServerError
+	if !errors.As(err, &se) || se.HasErrorCode(documentValidationFailure) {
+		return false
+	}
+
+	return se.HasErrorLabel("RetryableWriteError")
+}
+
+// isNoPrimaryError reports whether err means there was no primary to serve the operation, as during an election
+func isNoPrimaryError(err error) bool {
+	var sse topology.ServerSelectionError
+	if errors.As(err, &sse) {
+		return true
+	}
+
+	var se mongo.ServerError
+	if !errors.As(err, &se) {
+		return false
+	}
+
+	for _, code := range notPrimaryCodes {
+		if se.HasErrorCode(code) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isCursorLostError reports whether err means the server discarded the cursor, as when a capped collection overwrites
+// the document a tailable cursor was positioned on
+func isCursorLostError(err error) bool {
+	var se mongo.ServerError
+	if !errors.As(err, &se) {
+		return false
+	}
+
+	for _, code := range cursorLostCodes {
+		if se.HasErrorCode(code) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isResumableStreamError reports whether a change stream that failed with err can be reopened from its resume token,
+// as after a network error or an election, rather than after an error such as ChangeStreamHistoryLost
+func isResumableStreamError(err error) bool {
+	if mongo.IsNetworkError(err) || isNoPrimaryError(err) || isCursorLostError(err) {
+		return true
+	}
+
+	var se mongo.ServerError
+	return errors.As(err, &se) && se.HasErrorLabel("ResumableChangeStreamError")
+}
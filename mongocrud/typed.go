@@ -0,0 +1,217 @@
+package mongocrud
+
+import (
+	// Standard
+	"context"
+	"time"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// TypedCollection wraps a DatabaseCollection whose documents all decode into T
+type TypedCollection[T any] struct {
+	collection *DatabaseCollection
+}
+
+// ChangeEvent is a change stream event whose full document is decoded into T. FullDocument is nil for deletes.
+type ChangeEvent[T any] struct {
+	OperationType string   `bson:"operationType"`
+	DocumentKey   bson.Raw `bson:"documentKey"`
+	FullDocument  *T       `bson:"fullDocument"`
+}
+
+// NewTypedCollection wraps the collection so that reads decode straight into T
+func NewTypedCollection[T any](c *DatabaseCollection) *TypedCollection[T] {
+	return &TypedCollection[T]{collection: c}
+}
+
+// TypedCollections returns a TypedCollection of T for each named collection, registering any collection the client
+// does not know about yet
+func TypedCollections[T any](client *DatabaseClient, names ...string) map[string]*TypedCollection[T] {
+	resp := make(map[string]*TypedCollection[T], len(names))
+
+	for _, name := range names {
+		resp[name] = NewTypedCollection[T](client.collectionOrAdd(name))
+	}
+
+	return resp
+}
+
+// Get returns the first document whose field matches the value, decoded into T
+func (t *TypedCollection[T]) Get(ctx context.Context, by, value string) (*T, error) {
+	return t.get(ctx, by, value)
+}
+
+// GetWithFields returns the first document whose field matches the value with only the given fields fetched, leaving
+// every other field of T at its zero value
+func (t *TypedCollection[T]) GetWithFields(ctx context.Context, by, value string, fields ...string) (*T, error) {
+	projection := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		projection = append(projection, bson.E{Key: field, Value: 1})
+	}
+
+	return t.get(ctx, by, value, options.FindOne().SetProjection(projection))
+}
+
+func (t *TypedCollection[T]) get(ctx context.Context, by, value string, opts ...*options.FindOneOptions) (*T, error) {
+	item, err := t.collection.GetItem(ctx, by, value, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeTyped[T](item)
+}
+
+// Update replaces the stored document with the item, which must have an ID, and returns it as stored. It returns
+// ErrorNotFound when no document has the item's ID.
+func (t *TypedCollection[T]) Update(ctx context.Context, item *T) (*T, error) {
+	res, err := t.collection.UpdateItem(ctx, item)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeTyped[T](res)
+}
+
+// Delete removes the document with the id, returning ErrorNotFound when there is none
+func (t *TypedCollection[T]) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return t.collection.DeleteItem(ctx, id)
+}
+
+func decodeTyped[T any](res *mongo.SingleResult) (*T, error) {
+	resp := new(T)
+	if err := res.Decode(resp); err != nil {
+		return nil, ErrorDecodeFailed
+	}
+
+	return resp, nil
+}
+
+// Watch streams the collection's changes matching the pipeline as typed events until the context is cancelled. Updates
+// carry the current document. When the stream fails with an error it can resume from, such as a brief disconnect, it
+// is reopened from the last resume token so that no events are lost. Both channels are closed once the stream ends; an
+// invalidate event, after the collection was dropped or renamed, is delivered and then ends it with
+// ErrorStreamInvalidated, and a failure it cannot resume from, such as ChangeStreamHistoryLost, is sent on the error
+// channel.
+func (t *TypedCollection[T]) Watch(ctx context.Context, pipeline mongo.Pipeline) (<-chan ChangeEvent[T], <-chan error, error) {
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+
+	stream, err := t.collection.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	events := make(chan ChangeEvent[T])
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(events)
+
+		for {
+			for stream.Next(ctx) {
+				var event ChangeEvent[T]
+				if err := stream.Decode(&event); err != nil {
+					t.warn("change event decode failed", err)
+					continue
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					stream.Close(context.Background())
+					return
+				}
+
+				if event.OperationType == "invalidate" {
+					stream.Close(context.Background())
+					errs <- ErrorStreamInvalidated
+					return
+				}
+			}
+
+			err := stream.Err()
+			if token := stream.ResumeToken(); token != nil {
+				opts.SetResumeAfter(token)
+			}
+			stream.Close(context.Background())
+
+			for {
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil && !isResumableStreamError(err) {
+					t.warn("change stream failed", err)
+					errs <- operationError(ctx, err, ErrorGetFailed)
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(tailRetryInterval):
+				}
+
+				// The driver's errors are kept unmapped so that a failed reopen can be told apart as well
+				stream, err = t.collection.collection.Watch(ctx, pipeline, opts)
+				if err == nil {
+					break
+				}
+				t.warn("change stream resume failed", err)
+			}
+		}
+	}()
+
+	return events, errs, nil
+}
+
+func (t *TypedCollection[T]) warn(msg string, err error) {
+	if t.collection.logger == nil {
+		return
+	}
+
+	t.collection.logger.Warn(msg,
+		zap.String("collection", t.collection.name),
+		zap.Error(err),
+	)
+}
+
+// Page returns one page of the documents matching the filter decoded into T, along with the total number of matching
+// documents for page controls. Pages start at 1, and a page below 1 is treated as the first. A nil sort falls back to
+// the collection's DefaultSort.
+func (t *TypedCollection[T]) Page(ctx context.Context, filter bson.D, page, size int64, sort bson.D) ([]T, int64, error) {
+	if size <= 0 {
+		return nil, 0, ErrorPageSizeInvalid
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	total, err := t.collection.CountItems(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSkip((page - 1) * size).SetLimit(size)
+	if sort != nil {
+		opts.SetSort(sort)
+	}
+
+	cursor, err := t.collection.GetItems(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// size usually comes from the request, so the slice grows with the documents read rather than being sized by it
+	items := []T{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, 0, operationError(ctx, err, ErrorDecodeFailed)
+	}
+
+	return items, total, nil
+}
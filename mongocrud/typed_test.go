@@ -0,0 +1,66 @@
+package mongocrud
+
+import (
+	// Standard
+	"context"
+	"errors"
+	"testing"
+
+	// External
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type typedTestItem struct {
+	ID primitive.ObjectID `bson:"_id"`
+}
+
+type ctxKey struct{}
+
+// deleteCollection stubs DeleteOne, reporting deleted documents removed and recording the context it was called with
+type deleteCollection struct {
+	mongoCollection
+
+	deleted int64
+	ctx     context.Context
+}
+
+func (m *deleteCollection) DeleteOne(ctx context.Context, _ interface{}, _ ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	m.ctx = ctx
+	return &mongo.DeleteResult{DeletedCount: m.deleted}, nil
+}
+
+func TestTypedDeleteReturnsNotFoundWhenNothingDeleted(t *testing.T) {
+	mock := &deleteCollection{}
+	typed := NewTypedCollection[typedTestItem](&DatabaseCollection{name: "items", collection: mock})
+
+	err := typed.Delete(context.Background(), primitive.NewObjectID())
+	if !errors.Is(err, ErrorNotFound) {
+		t.Fatalf("Delete() error = %v, want %v", err, ErrorNotFound)
+	}
+}
+
+func TestTypedDeleteNotFoundMatchesDeleteFailed(t *testing.T) {
+	mock := &deleteCollection{}
+	typed := NewTypedCollection[typedTestItem](&DatabaseCollection{name: "items", collection: mock})
+
+	err := typed.Delete(context.Background(), primitive.NewObjectID())
+	if !errors.Is(err, ErrorDeleteFailed) {
+		t.Fatalf("Delete() error = %v, want it to match %v", err, ErrorDeleteFailed)
+	}
+}
+
+func TestTypedDeleteUsesCallerContext(t *testing.T) {
+	mock := &deleteCollection{deleted: 1}
+	typed := NewTypedCollection[typedTestItem](&DatabaseCollection{name: "items", collection: mock})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "caller")
+	if err := typed.Delete(ctx, primitive.NewObjectID()); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if mock.ctx == nil || mock.ctx.Value(ctxKey{}) != "caller" {
+		t.Error("DeleteOne was not called with the caller's context")
+	}
+}